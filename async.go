@@ -1,10 +1,42 @@
 package onedrive
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Status values reported by the monitor of an asynchronous job.
+const (
+	AsyncJobNotStarted = "notStarted"
+	AsyncJobInProgress = "inProgress"
+	AsyncJobCompleted  = "completed"
+	AsyncJobFailed     = "failed"
+)
+
+const (
+	defaultPollInterval    = time.Second
+	defaultMaxPollInterval = 30 * time.Second
+)
+
 // AsyncJob stores the location (URL) which can be pinged with CheckStatus() to
-// check progress of an Async job.
+// check progress of an Async job, or waited on with Wait() for the item it
+// creates.
 type AsyncJob struct {
 	*OneDrive
 	Location string
+	// PollInterval is the delay before the monitor is first polled again by
+	// Wait. It is doubled after every poll, up to MaxPollInterval, unless the
+	// monitor asks for a different delay with Retry-After.
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+
+	// driveID identifies the drive the created item will be in, which is
+	// needed when the monitor only reports the ID of the item.
+	driveID string
 }
 
 // AsyncJobStatus provides information on the status of a asynchronous job progress.
@@ -12,20 +44,151 @@ type AsyncJobStatus struct {
 	Operation          string  `json:"operation"`
 	PercentageComplete float64 `json:"percentageComplete"`
 	Status             string  `json:"status"`
+	StatusDescription  string  `json:"statusDescription,omitempty"`
+	// ResourceID is the ID of the created item, reported by some monitors
+	// once the job has completed.
+	ResourceID string      `json:"resourceId,omitempty"`
+	Error      *InnerError `json:"error,omitempty"`
+}
+
+// AsyncJobError is returned by Wait when the service reports that the job
+// failed.
+type AsyncJobError struct {
+	Status *AsyncJobStatus
+	// Err holds the error reported by the monitor, if there was one.
+	Err *Error
+}
+
+func (e *AsyncJobError) Error() string {
+	msg := fmt.Sprintf("asynchronous job %s", e.Status.Status)
+	if e.Status.Operation != "" {
+		msg = fmt.Sprintf("asynchronous %s job %s", e.Status.Operation, e.Status.Status)
+	}
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	if e.Status.StatusDescription != "" {
+		return msg + ": " + e.Status.StatusDescription
+	}
+	return msg
+}
+
+func (e *AsyncJobError) Unwrap() error {
+	if e.Err == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// newAsyncJob returns the job monitored at the Location of an accepted
+// asynchronous request.
+func (od *OneDrive) newAsyncJob(resp *http.Response, driveID string) (*AsyncJob, error) {
+	location, err := resp.Location()
+	if err != nil {
+		return nil, fmt.Errorf("onedrive: asynchronous job has no monitor location: %w", err)
+	}
+	return &AsyncJob{OneDrive: od, Location: location.String(), driveID: driveID}, nil
 }
 
 // CheckStatus returns a new AsyncJobStatus
 func (aj AsyncJob) CheckStatus() (*AsyncJobStatus, error) {
-	req, err := aj.newRequest("GET", aj.Location, nil, nil)
+	return aj.CheckStatusContext(context.Background())
+}
+
+// CheckStatusContext is like CheckStatus but carries a context.
+func (aj AsyncJob) CheckStatusContext(ctx context.Context) (*AsyncJobStatus, error) {
+	status, _, _, err := aj.poll(ctx)
+	return status, err
+}
+
+// Wait polls the monitor until the job finishes and returns the item it
+// created. progress, if not nil, is called with the status reported by every
+// poll. A job which the service reports as failed returns an *AsyncJobError.
+func (aj AsyncJob) Wait(progress func(*AsyncJobStatus)) (*Item, error) {
+	return aj.WaitContext(context.Background(), progress)
+}
+
+// WaitContext is like Wait but carries a context.
+func (aj AsyncJob) WaitContext(ctx context.Context, progress func(*AsyncJobStatus)) (*Item, error) {
+	interval := aj.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	maxInterval := aj.MaxPollInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultMaxPollInterval
+	}
+
+	for {
+		status, itemURI, retryAfter, err := aj.poll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(status)
+		}
+
+		switch {
+		case itemURI != "":
+			item, _, err := aj.Items.getItem(ctx, itemURI, nil)
+			return item, err
+		case status.Status == AsyncJobCompleted:
+			if status.ResourceID == "" {
+				return nil, errors.New("onedrive: asynchronous job completed without a resource ID")
+			}
+			item, _, err := aj.Items.getItem(ctx, driveItemURIFromID(aj.driveID, status.ResourceID), nil)
+			return item, err
+		case status.Status == AsyncJobFailed:
+			jobErr := &AsyncJobError{Status: status}
+			if status.Error != nil {
+				jobErr.Err = &Error{InnerError: *status.Error}
+			}
+			return nil, jobErr
+		}
+
+		wait := interval
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// poll fetches the status of the job once. The monitor is pre-authenticated,
+// so it is requested without credentials. Once the job has completed the
+// monitor may redirect to the created item, in which case its URI is returned
+// alongside a completed status.
+func (aj AsyncJob) poll(ctx context.Context) (status *AsyncJobStatus, itemURI string, retryAfter time.Duration, err error) {
+	req, err := aj.newRequest(ctx, "GET", aj.Location, nil, nil)
 	if err != nil {
-		return nil, err
+		return nil, "", 0, err
 	}
 
-	ajs := new(AsyncJobStatus)
-	_, err = aj.do(req, ajs)
+	resp, err := aj.doStream(withoutRedirects(aj.preauthClient()), req)
 	if err != nil {
-		return nil, err
+		return nil, "", 0, err
 	}
+	defer resp.Body.Close()
 
-	return ajs, nil
+	if isRedirect(resp) {
+		location, err := resp.Location()
+		if err != nil {
+			return nil, "", 0, err
+		}
+		return &AsyncJobStatus{Status: AsyncJobCompleted, PercentageComplete: 100}, location.String(), 0, nil
+	}
+
+	status = new(AsyncJobStatus)
+	if err := json.NewDecoder(resp.Body).Decode(status); err != nil {
+		return nil, "", 0, err
+	}
+	if until, err := calculateThrottle(time.Now(), resp.Header.Get("Retry-After")); err == nil {
+		retryAfter = time.Until(until)
+	}
+	return status, "", retryAfter, nil
 }
@@ -0,0 +1,192 @@
+package onedrive
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCopyWait(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.Client = &http.Client{Transport: bearerTransport{}}
+
+	mux.HandleFunc("/drive/items/some-id/action.copy", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Prefer"), "respond-async"; got != want {
+			t.Errorf("Got %q Expected %q", got, want)
+		}
+		w.Header().Set("Location", server.URL+"/monitor/job")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	polls := 0
+	mux.HandleFunc("/monitor/job", func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Expected the monitor to be polled without credentials, got %q", auth)
+		}
+		polls++
+		if polls < 3 {
+			fileWrapperHandler("fixtures/async.inProgress.json", http.StatusAccepted)(w, r)
+			return
+		}
+		http.Redirect(w, r, "/drive/items/0123456789abc!101", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/drive/items/0123456789abc!101", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), "Bearer secret"; got != want {
+			t.Errorf("Got %q Expected %q", got, want)
+		}
+		fileWrapperHandler("fixtures/item.folder.valid.json", http.StatusOK)(w, r)
+	})
+
+	job, _, err := oneDrive.Items.Copy("some-id", "copy", ItemReference{ID: "parent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job.PollInterval = time.Millisecond
+
+	var statuses []*AsyncJobStatus
+	item, err := job.Wait(func(status *AsyncJobStatus) {
+		statuses = append(statuses, status)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := item.ID, "0123456789abc!101"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+
+	expected := []struct {
+		status  string
+		percent float64
+	}{
+		{AsyncJobInProgress, 58.2},
+		{AsyncJobInProgress, 58.2},
+		{AsyncJobCompleted, 100},
+	}
+	if got, want := len(statuses), len(expected); got != want {
+		t.Fatalf("Got %d Expected %d progress updates", got, want)
+	}
+	for i, tst := range expected {
+		if got, want := statuses[i].Status, tst.status; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := statuses[i].PercentageComplete, tst.percent; got != want {
+			t.Errorf("[%d] Got %v Expected %v", i, got, want)
+		}
+	}
+}
+
+func TestUploadFromURLWaitResourceID(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.Graph = true
+
+	mux.HandleFunc("/me/drive/items/folder-id/children", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/monitor/job")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/monitor/job", fileWrapperHandler("fixtures/async.completed.json", http.StatusOK))
+	mux.HandleFunc("/me/drive/items/0123456789abc!101", fileWrapperHandler("fixtures/item.folder.valid.json", http.StatusOK))
+
+	job, _, err := oneDrive.Items.UploadFromURL("folder-id", "file", "http://example.com/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := job.Location, server.URL+"/monitor/job"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+
+	item, err := job.Wait(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := item.ID, "0123456789abc!101"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestCopyWaitForDrive(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.Graph = true
+
+	mux.HandleFunc("/drives/b!business/items/some-id/copy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/monitor/job")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/monitor/job", fileWrapperHandler("fixtures/async.completed.json", http.StatusOK))
+	mux.HandleFunc("/drives/other/items/0123456789abc!101", fileWrapperHandler("fixtures/item.folder.valid.json", http.StatusOK))
+
+	job, _, err := oneDrive.Items.ForDrive("b!business").Copy("some-id", "", ItemReference{DriveID: "other", ID: "parent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := job.Wait(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAsyncJobWaitFailed(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/monitor/job", fileWrapperHandler("fixtures/async.failed.json", http.StatusOK))
+
+	job := AsyncJob{OneDrive: oneDrive, Location: server.URL + "/monitor/job"}
+	_, err := job.Wait(nil)
+
+	var jobErr *AsyncJobError
+	if !errors.As(err, &jobErr) {
+		t.Fatalf("Expected an *AsyncJobError, got: %v", err)
+	}
+	if got, want := jobErr.Status.Operation, "ItemCopy"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected %v to match ErrQuotaExceeded", err)
+	}
+}
+
+func TestAsyncJobWaitCancelled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/monitor/job", fileWrapperHandler("fixtures/async.inProgress.json", http.StatusAccepted))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := AsyncJob{OneDrive: oneDrive, Location: server.URL + "/monitor/job", PollInterval: time.Hour}
+	_, err := job.WaitContext(ctx, func(*AsyncJobStatus) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Got %v Expected %v", err, context.Canceled)
+	}
+}
+
+func TestCopyWithoutLocation(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/items/some-id/action.copy", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	if _, _, err := oneDrive.Items.Copy("some-id", "copy", ItemReference{ID: "parent"}); err == nil {
+		t.Fatal("Expected an error for a response without a monitor location")
+	}
+}
+
+func TestCheckStatus(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/monitor/job", fileWrapperHandler("fixtures/async.inProgress.json", http.StatusAccepted))
+
+	job := AsyncJob{OneDrive: oneDrive, Location: server.URL + "/monitor/job"}
+	status, err := job.CheckStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := status.Status, AsyncJobInProgress; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
@@ -0,0 +1,221 @@
+// Package auth implements the OAuth 2.0 flows needed to obtain access tokens
+// for the OneDrive API, keeps them refreshed and persists them between runs.
+//
+// The client returned by NewClient can be passed straight to
+// onedrive.NewOneDrive:
+//
+//	conf := &auth.Config{ClientID: "...", Scopes: []string{"Files.ReadWrite", "offline_access"}}
+//	tok, err := conf.AuthorizeLocal(ctx, openBrowser)
+//	...
+//	ts := conf.TokenSource(tok, &auth.FileStore{Path: "token.json"})
+//	od := onedrive.NewOneDrive(auth.NewClient(ts), false)
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// expiryDelta is subtracted from a token's expiry so that it is refreshed
+// before requests start failing with it.
+const expiryDelta = time.Minute
+
+// Endpoint contains the authorization server URLs of an identity platform.
+type Endpoint struct {
+	AuthURL       string
+	TokenURL      string
+	DeviceAuthURL string
+}
+
+// MicrosoftAccount is the endpoint for personal Microsoft accounts using the
+// legacy OneDrive API.
+// See: http://onedrive.github.io/auth/msa_oauth.htm
+var MicrosoftAccount = Endpoint{
+	AuthURL:  "https://login.live.com/oauth20_authorize.srf",
+	TokenURL: "https://login.live.com/oauth20_token.srf",
+}
+
+// AzureAD returns the Microsoft identity platform endpoint for a tenant. The
+// tenant may be a tenant ID or domain, or one of "common", "organizations"
+// and "consumers".
+func AzureAD(tenant string) Endpoint {
+	if tenant == "" {
+		tenant = "common"
+	}
+	base := "https://login.microsoftonline.com/" + url.PathEscape(tenant) + "/oauth2/v2.0"
+	return Endpoint{
+		AuthURL:       base + "/authorize",
+		TokenURL:      base + "/token",
+		DeviceAuthURL: base + "/devicecode",
+	}
+}
+
+// Config describes an application registered with the identity platform.
+type Config struct {
+	ClientID string
+	// ClientSecret is required for the client credentials flow and for
+	// confidential clients. Public clients leave it empty.
+	ClientSecret string
+	Endpoint     Endpoint
+	// RedirectURL is where the authorization code flow sends the user back
+	// to. AuthorizeLocal overrides it with its loopback listener.
+	RedirectURL string
+	Scopes      []string
+	// HTTPClient is used to talk to the token endpoint. If nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+// Token is an OAuth 2.0 token as returned by the token endpoint.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+}
+
+// Valid reports whether the token holds an access token which is not about
+// to expire.
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+// tokenJSON is the wire format of a token endpoint response.
+type tokenJSON struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Scope        string `json:"scope"`
+}
+
+// RetrieveError is returned when the token endpoint rejects a request.
+// See: https://tools.ietf.org/html/rfc6749#section-5.2
+type RetrieveError struct {
+	StatusCode  int
+	ErrorCode   string `json:"error"`
+	Description string `json:"error_description"`
+	Body        []byte
+}
+
+func (e *RetrieveError) Error() string {
+	if e.ErrorCode == "" {
+		return fmt.Sprintf("auth: token request failed with status %d", e.StatusCode)
+	}
+	if e.Description == "" {
+		return "auth: " + e.ErrorCode
+	}
+	return fmt.Sprintf("auth: %s: %s", e.ErrorCode, e.Description)
+}
+
+// ErrNoRefreshToken is returned when an expired token cannot be refreshed.
+var ErrNoRefreshToken = errors.New("auth: token expired and has no refresh token")
+
+func (c *Config) client() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Config) scope() string {
+	return strings.Join(c.Scopes, " ")
+}
+
+// postForm sends a form to an endpoint of the authorization server and
+// decodes a successful JSON response into v.
+func (c *Config) postForm(ctx context.Context, endpoint string, form url.Values, v interface{}) error {
+	form.Set("client_id", c.ClientID)
+	if c.ClientSecret != "" {
+		form.Set("client_secret", c.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		retrieveErr := &RetrieveError{StatusCode: resp.StatusCode, Body: body}
+		json.Unmarshal(body, retrieveErr)
+		return retrieveErr
+	}
+	return json.Unmarshal(body, v)
+}
+
+// retrieveToken requests a token from the token endpoint with the given grant.
+func (c *Config) retrieveToken(ctx context.Context, form url.Values) (*Token, error) {
+	if form.Get("scope") == "" && len(c.Scopes) > 0 {
+		form.Set("scope", c.scope())
+	}
+
+	tj := new(tokenJSON)
+	if err := c.postForm(ctx, c.Endpoint.TokenURL, form, tj); err != nil {
+		return nil, err
+	}
+	if tj.AccessToken == "" {
+		return nil, errors.New("auth: server response missing access_token")
+	}
+
+	tok := &Token{
+		AccessToken:  tj.AccessToken,
+		TokenType:    tj.TokenType,
+		RefreshToken: tj.RefreshToken,
+		Scope:        tj.Scope,
+	}
+	if tj.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(tj.ExpiresIn) * time.Second)
+	}
+	return tok, nil
+}
+
+// Refresh exchanges a refresh token for a new token. The identity platform
+// rotates refresh tokens, so the returned token should replace the old one;
+// if the server does not issue a new refresh token the old one is kept.
+func (c *Config) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	if refreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+	tok, err := c.retrieveToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = refreshToken
+	}
+	return tok, nil
+}
+
+// ClientCredentialsToken obtains an application token with the client
+// credentials grant. It requires a ClientSecret and an Azure AD endpoint.
+func (c *Config) ClientCredentialsToken(ctx context.Context) (*Token, error) {
+	return c.retrieveToken(ctx, url.Values{
+		"grant_type": {"client_credentials"},
+	})
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	mux    *http.ServeMux
+	server *httptest.Server
+	config *Config
+)
+
+func setup() {
+	mux = http.NewServeMux()
+	server = httptest.NewServer(mux)
+	config = &Config{
+		ClientID: "client-id",
+		Scopes:   []string{"Files.ReadWrite", "offline_access"},
+		Endpoint: Endpoint{
+			AuthURL:       server.URL + "/authorize",
+			TokenURL:      server.URL + "/token",
+			DeviceAuthURL: server.URL + "/devicecode",
+		},
+	}
+}
+
+func teardown() {
+	server.Close()
+}
+
+func jsonHandler(status int, v interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+// tokenEndpoint is a stand-in token endpoint which issues numbered access
+// and refresh tokens and records the forms it received.
+type tokenEndpoint struct {
+	mu     sync.Mutex
+	issued int
+	forms  []map[string]string
+}
+
+func (te *tokenEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		panic(err)
+	}
+	te.mu.Lock()
+	te.issued++
+	form := make(map[string]string)
+	for k := range r.PostForm {
+		form[k] = r.PostForm.Get(k)
+	}
+	te.forms = append(te.forms, form)
+	n := te.issued
+	te.mu.Unlock()
+
+	jsonHandler(http.StatusOK, map[string]interface{}{
+		"access_token":  "access-" + string(rune('0'+n)),
+		"refresh_token": "refresh-" + string(rune('0'+n)),
+		"token_type":    "bearer",
+		"expires_in":    3600,
+	})(w, r)
+}
+
+func TestTokenValid(t *testing.T) {
+	tt := []struct {
+		tok   *Token
+		valid bool
+	}{
+		{nil, false},
+		{&Token{}, false},
+		{&Token{AccessToken: "a"}, true},
+		{&Token{AccessToken: "a", Expiry: time.Now().Add(time.Hour)}, true},
+		{&Token{AccessToken: "a", Expiry: time.Now().Add(30 * time.Second)}, false},
+		{&Token{AccessToken: "a", Expiry: time.Now().Add(-time.Hour)}, false},
+	}
+	for i, tst := range tt {
+		if got, want := tst.tok.Valid(), tst.valid; got != want {
+			t.Errorf("[%d] Got %t Expected %t", i, got, want)
+		}
+	}
+}
+
+func TestAzureAD(t *testing.T) {
+	tt := []struct {
+		tenant, tokenURL string
+	}{
+		{"", "https://login.microsoftonline.com/common/oauth2/v2.0/token"},
+		{"contoso.onmicrosoft.com", "https://login.microsoftonline.com/contoso.onmicrosoft.com/oauth2/v2.0/token"},
+	}
+	for i, tst := range tt {
+		if got, want := AzureAD(tst.tenant).TokenURL, tst.tokenURL; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	setup()
+	defer teardown()
+
+	te := new(tokenEndpoint)
+	mux.Handle("/token", te)
+
+	tok, err := config.Refresh(context.Background(), "old-refresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tok.AccessToken, "access-1"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := tok.RefreshToken, "refresh-1"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if !tok.Valid() {
+		t.Errorf("Expected token expiring at %s to be valid", tok.Expiry)
+	}
+
+	form := te.forms[0]
+	if got, want := form["grant_type"], "refresh_token"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := form["refresh_token"], "old-refresh"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := form["scope"], "Files.ReadWrite offline_access"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestRefreshKeepsRefreshToken(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/token", jsonHandler(http.StatusOK, map[string]interface{}{
+		"access_token": "access",
+		"expires_in":   3600,
+	}))
+
+	tok, err := config.Refresh(context.Background(), "old-refresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tok.RefreshToken, "old-refresh"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestRefreshRejected(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/token", jsonHandler(http.StatusBadRequest, map[string]string{
+		"error":             "invalid_grant",
+		"error_description": "The refresh token has expired.",
+	}))
+
+	_, err := config.Refresh(context.Background(), "old-refresh")
+
+	var retrieveErr *RetrieveError
+	if !errors.As(err, &retrieveErr) {
+		t.Fatalf("Got %T Expected *RetrieveError", err)
+	}
+	if got, want := retrieveErr.ErrorCode, "invalid_grant"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := retrieveErr.StatusCode, http.StatusBadRequest; got != want {
+		t.Errorf("Got %d Expected %d", got, want)
+	}
+}
+
+func TestClientCredentialsToken(t *testing.T) {
+	setup()
+	defer teardown()
+
+	te := new(tokenEndpoint)
+	mux.Handle("/token", te)
+	config.ClientSecret = "secret"
+	config.Scopes = []string{"https://graph.microsoft.com/.default"}
+
+	if _, err := config.ClientCredentialsToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	form := te.forms[0]
+	for k, v := range map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     "client-id",
+		"client_secret": "secret",
+		"scope":         "https://graph.microsoft.com/.default",
+	} {
+		if got, want := form[k], v; got != want {
+			t.Errorf("%s: Got %q Expected %q", k, got, want)
+		}
+	}
+}
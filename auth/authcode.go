@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PKCE holds a code verifier for the Proof Key for Code Exchange extension,
+// which protects the authorization code flow for public clients.
+// See: https://tools.ietf.org/html/rfc7636
+type PKCE struct {
+	Verifier string
+}
+
+// NewPKCE returns a PKCE with a random verifier.
+func NewPKCE() (*PKCE, error) {
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	return &PKCE{Verifier: verifier}, nil
+}
+
+// Challenge returns the S256 code challenge for the verifier.
+func (p *PKCE) Challenge() string {
+	sum := sha256.Sum256([]byte(p.Verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthCodeURL returns the URL of the consent page the user must visit to
+// authorize the application. state is echoed back to the redirect URL and
+// should be checked there; pkce may be nil for confidential clients.
+func (c *Config) AuthCodeURL(state string, pkce *PKCE) string {
+	v := url.Values{
+		"response_type": {"code"},
+		"client_id":     {c.ClientID},
+		"state":         {state},
+	}
+	if c.RedirectURL != "" {
+		v.Set("redirect_uri", c.RedirectURL)
+	}
+	if len(c.Scopes) > 0 {
+		v.Set("scope", c.scope())
+	}
+	if pkce != nil {
+		v.Set("code_challenge", pkce.Challenge())
+		v.Set("code_challenge_method", "S256")
+	}
+
+	sep := "?"
+	if strings.Contains(c.Endpoint.AuthURL, "?") {
+		sep = "&"
+	}
+	return c.Endpoint.AuthURL + sep + v.Encode()
+}
+
+// Exchange converts an authorization code into a token. pkce must be the one
+// used to build the AuthCodeURL, or nil if none was used.
+func (c *Config) Exchange(ctx context.Context, code string, pkce *PKCE) (*Token, error) {
+	v := url.Values{
+		"grant_type": {"authorization_code"},
+		"code":       {code},
+	}
+	if c.RedirectURL != "" {
+		v.Set("redirect_uri", c.RedirectURL)
+	}
+	if pkce != nil {
+		v.Set("code_verifier", pkce.Verifier)
+	}
+	return c.retrieveToken(ctx, v)
+}
+
+// AuthorizeLocal runs the whole authorization code flow for a native
+// application. It listens for the redirect on a loopback address, calls open
+// with the consent page URL (typically to launch a browser) and exchanges the
+// code it receives. If RedirectURL is set it must be an http URL on a loopback
+// host such as localhost or 127.0.0.1; its port is used if it has one,
+// otherwise a free port is chosen and added to the redirect_uri, as the
+// identity platform allows for loopback redirects. Without a RedirectURL a
+// free port on 127.0.0.1 is used.
+func (c *Config) AuthorizeLocal(ctx context.Context, open func(authURL string) error) (*Token, error) {
+	listener, redirect, err := listenLoopback(c.RedirectURL)
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+
+	callbackPath := redirect.Path
+	if callbackPath == "" {
+		callbackPath = "/"
+	}
+	local := *c
+	local.RedirectURL = redirect.String()
+
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	pkce, err := NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		// Only the redirect for this flow ends it; anything else the browser
+		// or another local process sends, such as a favicon request or a
+		// redirect with a stale state, is turned away.
+		if r.URL.Path != callbackPath || q.Get("state") != state {
+			http.NotFound(w, r)
+			return
+		}
+
+		var res result
+		switch {
+		case q.Get("error") != "":
+			res.err = &RetrieveError{ErrorCode: q.Get("error"), Description: q.Get("error_description")}
+		case q.Get("code") == "":
+			res.err = errors.New("auth: redirect is missing the authorization code")
+		default:
+			res.code = q.Get("code")
+		}
+
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Authorization complete, you can close this window.")
+		}
+		select {
+		case results <- res:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if err := open(local.AuthCodeURL(state, pkce)); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-results:
+		if res.err != nil {
+			return nil, res.err
+		}
+		return local.Exchange(ctx, res.code, pkce)
+	}
+}
+
+// listenLoopback listens on the loopback address of redirectURL and returns
+// the redirect URL completed with the port the listener was bound to.
+func listenLoopback(redirectURL string) (net.Listener, *url.URL, error) {
+	if redirectURL == "" {
+		redirectURL = "http://127.0.0.1/"
+	}
+	u, err := url.Parse(redirectURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	host := u.Hostname()
+	if u.Scheme != "http" || !isLoopback(host) {
+		return nil, nil, fmt.Errorf("auth: redirect URL %q is not a loopback http URL", redirectURL)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "0"
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, port, err = net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		listener.Close()
+		return nil, nil, err
+	}
+	redirect := *u
+	redirect.Host = net.JoinHostPort(host, port)
+	return listener, &redirect, nil
+}
+
+// isLoopback reports whether host names or is a loopback address.
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
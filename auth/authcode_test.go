@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestPKCEChallenge(t *testing.T) {
+	// BASE64URL(SHA256("some-verifier")) without padding.
+	pkce := &PKCE{Verifier: "some-verifier"}
+	if got, want := pkce.Challenge(), "ubly7tj-d2Aa-jlUqnEi6yYmg0jdjXMuNWE3kM3U63g"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	setup()
+	defer teardown()
+	config.RedirectURL = "http://127.0.0.1:8080/callback"
+
+	u, err := url.Parse(config.AuthCodeURL("some-state", &PKCE{Verifier: "verifier"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	for k, v := range map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-id",
+		"state":                 "some-state",
+		"redirect_uri":          "http://127.0.0.1:8080/callback",
+		"scope":                 "Files.ReadWrite offline_access",
+		"code_challenge_method": "S256",
+	} {
+		if got, want := q.Get(k), v; got != want {
+			t.Errorf("%s: Got %q Expected %q", k, got, want)
+		}
+	}
+}
+
+// browser follows the consent page URL the way the identity platform would,
+// redirecting straight back to the application with the given query.
+func browser(query url.Values) func(string) error {
+	return func(authURL string) error {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			return err
+		}
+		redirect, err := url.Parse(u.Query().Get("redirect_uri"))
+		if err != nil {
+			return err
+		}
+		q := redirect.Query()
+		q.Set("state", u.Query().Get("state"))
+		for k := range query {
+			q.Set(k, query.Get(k))
+		}
+		redirect.RawQuery = q.Encode()
+
+		go func() {
+			resp, err := http.Get(redirect.String())
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+}
+
+func TestAuthorizeLocal(t *testing.T) {
+	setup()
+	defer teardown()
+
+	te := new(tokenEndpoint)
+	mux.Handle("/token", te)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tok, err := config.AuthorizeLocal(ctx, browser(url.Values{"code": {"some-code"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tok.AccessToken, "access-1"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+
+	form := te.forms[0]
+	if got, want := form["code"], "some-code"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if form["code_verifier"] == "" {
+		t.Error("Expected a PKCE code verifier to be sent")
+	}
+}
+
+func TestAuthorizeLocalDenied(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := config.AuthorizeLocal(ctx, browser(url.Values{"error": {"access_denied"}}))
+
+	var retrieveErr *RetrieveError
+	if !errors.As(err, &retrieveErr) {
+		t.Fatalf("Got %T Expected *RetrieveError", err)
+	}
+	if got, want := retrieveErr.ErrorCode, "access_denied"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestAuthorizeLocalPortlessRedirect(t *testing.T) {
+	setup()
+	defer teardown()
+	config.RedirectURL = "http://localhost/callback"
+
+	te := new(tokenEndpoint)
+	mux.Handle("/token", te)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var redirectURI string
+	open := func(authURL string) error {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			return err
+		}
+		redirectURI = u.Query().Get("redirect_uri")
+		redirect, err := url.Parse(redirectURI)
+		if err != nil {
+			return err
+		}
+
+		// Stray requests must not end the flow.
+		for _, stray := range []string{"/favicon.ico", "/callback?state=wrong&code=other-code", "/callback"} {
+			resp, err := http.Get("http://" + redirect.Host + stray)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if got, want := resp.StatusCode, http.StatusNotFound; got != want {
+				t.Errorf("%s: Got %d Expected %d", stray, got, want)
+			}
+		}
+		return browser(url.Values{"code": {"some-code"}})(authURL)
+	}
+
+	if _, err := config.AuthorizeLocal(ctx, open); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := u.Hostname(), "localhost"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if u.Port() == "" || u.Port() == "0" {
+		t.Errorf("Expected the chosen port in the redirect URI, got %q", redirectURI)
+	}
+	if got, want := u.Path, "/callback"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	form := te.forms[0]
+	if got, want := form["code"], "some-code"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := form["redirect_uri"], redirectURI; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestAuthorizeLocalRejectsNonLoopback(t *testing.T) {
+	tt := []string{
+		"http://example.com/callback",
+		"http://192.168.1.10:8080/callback",
+		"https://localhost/callback",
+	}
+	for i, redirectURL := range tt {
+		setup()
+		config.RedirectURL = redirectURL
+
+		_, err := config.AuthorizeLocal(context.Background(), func(string) error {
+			t.Errorf("[%d] Expected the consent page not to be opened", i)
+			return nil
+		})
+		if err == nil {
+			t.Errorf("[%d] Expected an error for %q", i, redirectURL)
+		}
+		teardown()
+	}
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// DeviceCode is the response of the device authorization endpoint. The user
+// must visit VerificationURI and enter UserCode to authorize the device.
+// See: https://tools.ietf.org/html/rfc8628
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Interval        int64  `json:"interval"`
+	Message         string `json:"message"`
+	// Expiry is calculated from ExpiresIn when the code is issued.
+	Expiry time.Time `json:"-"`
+}
+
+// ErrDeviceCodeExpired is returned when the user did not authorize the device
+// before the device code expired.
+var ErrDeviceCodeExpired = errors.New("auth: device code expired")
+
+// DeviceAuth starts the device code flow, which suits applications without a
+// browser. The returned code should be shown to the user before calling
+// DeviceAccessToken.
+func (c *Config) DeviceAuth(ctx context.Context) (*DeviceCode, error) {
+	if c.Endpoint.DeviceAuthURL == "" {
+		return nil, errors.New("auth: endpoint does not support the device code flow")
+	}
+
+	v := url.Values{}
+	if len(c.Scopes) > 0 {
+		v.Set("scope", c.scope())
+	}
+	dc := new(DeviceCode)
+	if err := c.postForm(ctx, c.Endpoint.DeviceAuthURL, v, dc); err != nil {
+		return nil, err
+	}
+	if dc.ExpiresIn > 0 {
+		dc.Expiry = time.Now().Add(time.Duration(dc.ExpiresIn) * time.Second)
+	}
+	return dc, nil
+}
+
+// DeviceAccessToken polls the token endpoint until the user has authorized
+// the device, the code expires or ctx is done.
+func (c *Config) DeviceAccessToken(ctx context.Context, dc *DeviceCode) (*Token, error) {
+	interval := time.Duration(dc.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	for {
+		if !dc.Expiry.IsZero() && time.Now().After(dc.Expiry) {
+			return nil, ErrDeviceCodeExpired
+		}
+
+		tok, err := c.retrieveToken(ctx, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {dc.DeviceCode},
+		})
+		if err == nil {
+			return tok, nil
+		}
+
+		var retrieveErr *RetrieveError
+		if !errors.As(err, &retrieveErr) {
+			return nil, err
+		}
+		switch retrieveErr.ErrorCode {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "expired_token":
+			return nil, ErrDeviceCodeExpired
+		default:
+			return nil, err
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestDeviceFlow(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/devicecode", jsonHandler(http.StatusOK, map[string]interface{}{
+		"device_code":      "device-code",
+		"user_code":        "ABCD-EFGH",
+		"verification_uri": "https://microsoft.com/devicelogin",
+		"expires_in":       900,
+		"interval":         1,
+	}))
+
+	te := new(tokenEndpoint)
+	polls := 0
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls == 1 {
+			jsonHandler(http.StatusBadRequest, map[string]string{"error": "authorization_pending"})(w, r)
+			return
+		}
+		te.ServeHTTP(w, r)
+	})
+
+	dc, err := config.DeviceAuth(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dc.UserCode, "ABCD-EFGH"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+
+	tok, err := config.DeviceAccessToken(context.Background(), dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tok.AccessToken, "access-1"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := te.forms[0]["device_code"], "device-code"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestDeviceFlowExpired(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/token", jsonHandler(http.StatusBadRequest, map[string]string{"error": "expired_token"}))
+
+	if _, err := config.DeviceAccessToken(context.Background(), &DeviceCode{DeviceCode: "device-code"}); err != ErrDeviceCodeExpired {
+		t.Errorf("Got %v Expected %v", err, ErrDeviceCodeExpired)
+	}
+}
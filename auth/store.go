@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/ggordan/go-onedrive/internal/atomicfile"
+)
+
+// ErrNoToken is returned by a TokenStore which does not hold a token yet.
+var ErrNoToken = errors.New("auth: no token available")
+
+// A TokenStore persists tokens between runs so that users do not have to
+// authorize the application again.
+type TokenStore interface {
+	Load() (*Token, error)
+	Save(tok *Token) error
+}
+
+// MemoryStore keeps a token in memory. The zero value is ready to use.
+type MemoryStore struct {
+	mu  sync.Mutex
+	tok *Token
+}
+
+// Load returns the stored token.
+func (s *MemoryStore) Load() (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok == nil {
+		return nil, ErrNoToken
+	}
+	tok := *s.tok
+	return &tok, nil
+}
+
+// Save replaces the stored token.
+func (s *MemoryStore) Save(tok *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *tok
+	s.tok = &stored
+	return nil
+}
+
+// FileStore keeps a token as JSON in a file which only the current user can
+// read.
+type FileStore struct {
+	Path string
+}
+
+// Load reads the token from the file.
+func (s *FileStore) Load() (*Token, error) {
+	b, err := readFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	tok := new(Token)
+	if err := json.Unmarshal(b, tok); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+// Save writes the token to the file, replacing it atomically.
+func (s *FileStore) Save(tok *Token) error {
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.Path, b, 0600)
+}
+
+// EncryptedFileStore keeps a token in a file encrypted with AES-GCM. The key
+// may be any secret; it is stretched to 256 bits with SHA-256.
+type EncryptedFileStore struct {
+	Path string
+	Key  []byte
+}
+
+func (s *EncryptedFileStore) aead() (cipher.AEAD, error) {
+	if len(s.Key) == 0 {
+		return nil, errors.New("auth: encrypted store requires a key")
+	}
+	key := sha256.Sum256(s.Key)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Load reads and decrypts the token from the file.
+func (s *EncryptedFileStore) Load() (*Token, error) {
+	aead, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	b, err := readFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < aead.NonceSize() {
+		return nil, errors.New("auth: encrypted token is truncated")
+	}
+	plain, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+	tok := new(Token)
+	if err := json.Unmarshal(plain, tok); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+// Save encrypts the token and writes it to the file, replacing it atomically.
+func (s *EncryptedFileStore) Save(tok *Token) error {
+	aead, err := s.aead()
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.Path, aead.Seal(nonce, nonce, plain, nil), 0600)
+}
+
+func readFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoToken
+	}
+	return b, err
+}
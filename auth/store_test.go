@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTokenStores(t *testing.T) {
+	dir := t.TempDir()
+
+	tt := []struct {
+		name  string
+		store TokenStore
+	}{
+		{"memory", new(MemoryStore)},
+		{"file", &FileStore{Path: filepath.Join(dir, "token.json")}},
+		{"encrypted", &EncryptedFileStore{Path: filepath.Join(dir, "token.enc"), Key: []byte("passphrase")}},
+	}
+	for _, tst := range tt {
+		if _, err := tst.store.Load(); err != ErrNoToken {
+			t.Errorf("[%s] Got %v Expected %v", tst.name, err, ErrNoToken)
+		}
+
+		tok := &Token{
+			AccessToken:  "access",
+			RefreshToken: "refresh",
+			TokenType:    "bearer",
+			Expiry:       time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		}
+		if err := tst.store.Save(tok); err != nil {
+			t.Fatalf("[%s] %s", tst.name, err)
+		}
+		loaded, err := tst.store.Load()
+		if err != nil {
+			t.Fatalf("[%s] %s", tst.name, err)
+		}
+		if !reflect.DeepEqual(loaded, tok) {
+			t.Errorf("[%s] Got %v Expected %v", tst.name, loaded, tok)
+		}
+	}
+}
+
+func TestFileStorePermissions(t *testing.T) {
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "token.json")}
+	if err := store.Save(&Token{AccessToken: "access"}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := info.Mode().Perm(), os.FileMode(0600); got != want {
+		t.Errorf("Got %s Expected %s", got, want)
+	}
+}
+
+func TestEncryptedFileStoreWrongKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.enc")
+	if err := (&EncryptedFileStore{Path: path, Key: []byte("right")}).Save(&Token{AccessToken: "access"}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(b, []byte("access")) || len(b) == 0 {
+		t.Fatalf("Expected the token to be encrypted, got %q", b)
+	}
+
+	if _, err := (&EncryptedFileStore{Path: path, Key: []byte("wrong")}).Load(); err == nil {
+		t.Error("Expected an error decrypting with the wrong key")
+	}
+}
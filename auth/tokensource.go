@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// A TokenSource supplies valid access tokens.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// refreshingSource returns its current token while it is valid and obtains a
+// new one through fetch once it expires. New tokens are written to the store.
+type refreshingSource struct {
+	mu    sync.Mutex
+	tok   *Token
+	fetch func(ctx context.Context, current *Token) (*Token, error)
+	store TokenStore
+}
+
+func (s *refreshingSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tok.Valid() {
+		return s.tok, nil
+	}
+
+	tok, err := s.fetch(ctx, s.tok)
+	if err != nil {
+		return nil, err
+	}
+	if s.store != nil {
+		if err := s.store.Save(tok); err != nil {
+			return nil, err
+		}
+	}
+	s.tok = tok
+	return tok, nil
+}
+
+// TokenSource returns a TokenSource which starts with tok and refreshes it
+// with its refresh token when it expires. If store is not nil, every
+// refreshed token is saved to it. If tok is nil, it is loaded from store.
+func (c *Config) TokenSource(tok *Token, store TokenStore) TokenSource {
+	return &refreshingSource{
+		tok:   tok,
+		store: store,
+		fetch: func(ctx context.Context, current *Token) (*Token, error) {
+			if current == nil && store != nil {
+				loaded, err := store.Load()
+				if err != nil && !errors.Is(err, ErrNoToken) {
+					return nil, err
+				}
+				if loaded.Valid() {
+					return loaded, nil
+				}
+				current = loaded
+			}
+			if current == nil {
+				return nil, ErrNoToken
+			}
+			return c.Refresh(ctx, current.RefreshToken)
+		},
+	}
+}
+
+// ClientCredentialsTokenSource returns a TokenSource which obtains a new
+// application token with the client credentials grant whenever the previous
+// one expires.
+func (c *Config) ClientCredentialsTokenSource(store TokenStore) TokenSource {
+	return &refreshingSource{
+		store: store,
+		fetch: func(ctx context.Context, _ *Token) (*Token, error) {
+			return c.ClientCredentialsToken(ctx)
+		},
+	}
+}
+
+// StaticTokenSource returns a TokenSource which always returns tok. It is
+// useful when the token is managed elsewhere.
+func StaticTokenSource(tok *Token) TokenSource {
+	return staticSource{tok}
+}
+
+type staticSource struct {
+	tok *Token
+}
+
+func (s staticSource) Token(context.Context) (*Token, error) {
+	return s.tok, nil
+}
+
+// Transport is an http.RoundTripper which authorizes every request with a
+// token from Source.
+type Transport struct {
+	Source TokenSource
+	// Base is the underlying RoundTripper. If nil, http.DefaultTransport is
+	// used.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tok, err := t.Source.Token(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	tokenType := tok.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+
+	// A RoundTripper must not modify the request it was given.
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", tokenType+" "+tok.AccessToken)
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(authorized)
+}
+
+// NewClient returns an HTTP client which authorizes its requests with tokens
+// from ts.
+func NewClient(ts TokenSource) *http.Client {
+	return &http.Client{Transport: &Transport{Source: ts}}
+}
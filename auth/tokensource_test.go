@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTokenSourceRefreshesExpiredToken(t *testing.T) {
+	setup()
+	defer teardown()
+
+	te := new(tokenEndpoint)
+	mux.Handle("/token", te)
+
+	store := new(MemoryStore)
+	expired := &Token{AccessToken: "expired", RefreshToken: "refresh-0", Expiry: time.Now().Add(-time.Minute)}
+	ts := config.TokenSource(expired, store)
+
+	for i := 0; i < 3; i++ {
+		tok, err := ts.Token(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := tok.AccessToken, "access-1"; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+	if got, want := len(te.forms), 1; got != want {
+		t.Errorf("Got %d Expected %d token requests", got, want)
+	}
+
+	stored, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := stored.RefreshToken, "refresh-1"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestTokenSourceLoadsFromStore(t *testing.T) {
+	setup()
+	defer teardown()
+
+	store := new(MemoryStore)
+	store.Save(&Token{AccessToken: "stored", Expiry: time.Now().Add(time.Hour)})
+
+	tok, err := config.TokenSource(nil, store).Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tok.AccessToken, "stored"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestTokenSourceWithoutToken(t *testing.T) {
+	setup()
+	defer teardown()
+
+	if _, err := config.TokenSource(nil, new(MemoryStore)).Token(context.Background()); err != ErrNoToken {
+		t.Errorf("Got %v Expected %v", err, ErrNoToken)
+	}
+}
+
+func TestClientCredentialsTokenSource(t *testing.T) {
+	setup()
+	defer teardown()
+
+	te := new(tokenEndpoint)
+	mux.Handle("/token", te)
+	config.ClientSecret = "secret"
+
+	ts := config.ClientCredentialsTokenSource(nil)
+	for i := 0; i < 2; i++ {
+		if _, err := ts.Token(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := len(te.forms), 1; got != want {
+		t.Errorf("Got %d Expected %d token requests", got, want)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var authorization string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer api.Close()
+
+	client := NewClient(StaticTokenSource(&Token{AccessToken: "secret", TokenType: "bearer"}))
+	req, _ := http.NewRequest("GET", api.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got, want := authorization, "Bearer secret"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got := req.Header.Get("Authorization"); got != "" {
+		t.Errorf("Expected the original request to be left untouched, got %q", got)
+	}
+}
@@ -0,0 +1,146 @@
+package onedrive
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithConflict(t *testing.T) {
+	tt := []struct {
+		graph       bool
+		conflict    ConflictBehaviour
+		uri         string
+		expectedOut string
+	}{
+		{false, "", "/drive/items/123/action.copy", "/drive/items/123/action.copy"},
+		{false, ConflictRename, "/drive/items/123/action.copy", "/drive/items/123/action.copy?@name.conflictBehavior=rename"},
+		{true, ConflictReplace, "/drive/items/123/copy", "/drive/items/123/copy?@microsoft.graph.conflictBehavior=replace"},
+		{false, ConflictFail, "/drive/items/123?a=b", "/drive/items/123?a=b&@name.conflictBehavior=fail"},
+	}
+	for i, tst := range tt {
+		od := NewOneDrive(http.DefaultClient, false)
+		od.Graph = tst.graph
+		if got, want := od.Items.OnConflict(tst.conflict).withConflict(tst.uri), tst.expectedOut; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestOnConflictRequests(t *testing.T) {
+	tt := []struct {
+		graph        bool
+		call         func(is *ItemService) error
+		expectedURI  string
+		expectedBody string
+	}{
+		{false, func(is *ItemService) error {
+			_, _, err := is.CreateFolder("root", "Reports")
+			return err
+		}, "/drive/root/children/Reports", `"@name.conflictBehavior":"rename"`},
+		{true, func(is *ItemService) error {
+			_, _, err := is.CreateFolder("root", "Reports")
+			return err
+		}, "/me/drive/root/children", `"@microsoft.graph.conflictBehavior":"rename"`},
+		{false, func(is *ItemService) error {
+			_, _, err := is.Upload("root", "Reports", strings.NewReader("content"), 7, nil)
+			return err
+		}, "/drive/root/children/Reports/content?@name.conflictBehavior=rename", ""},
+		{false, func(is *ItemService) error {
+			_, _, err := is.UploadFromURL("root", "Reports", "http://example.com/file")
+			return err
+		}, "/drive/root/children", `"@name.conflictBehavior":"rename"`},
+		{false, func(is *ItemService) error {
+			_, _, err := is.Copy("123", "Reports", ItemReference{ID: "root"})
+			return err
+		}, "/drive/items/123/action.copy?@name.conflictBehavior=rename", ""},
+		{true, func(is *ItemService) error {
+			_, _, err := is.Move(ItemReference{ID: "123"}, ItemReference{ID: "456"})
+			return err
+		}, "/me/drive/items/123?@microsoft.graph.conflictBehavior=rename", ""},
+		{false, func(is *ItemService) error {
+			_, _, err := is.CreateUploadSession("root", "Reports", is.conflictFor(nil))
+			return err
+		}, "/drive/root:/Reports:/upload.createSession", `"@name.conflictBehavior":"rename"`},
+	}
+	for i, tst := range tt {
+		setup()
+		var requestURI, body string
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			requestURI = r.RequestURI
+			b, _ := io.ReadAll(r.Body)
+			body = string(b)
+			w.Header().Set("Location", "/monitor/job")
+			fileTemplateHandler("fixtures/item.folder.renamed.json", http.StatusOK)(w, r)
+		})
+		oneDrive.Graph = tst.graph
+
+		if err := tst.call(oneDrive.Items.OnConflict(ConflictRename)); err != nil {
+			t.Errorf("[%d] %v", i, err)
+		}
+		if got, want := requestURI, tst.expectedURI; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if !strings.Contains(body, tst.expectedBody) {
+			t.Errorf("[%d] Expected %s in the request body, got %s", i, tst.expectedBody, body)
+		}
+		teardown()
+	}
+}
+
+func TestOnConflictRename(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/root/children/Reports", fileWrapperHandler("fixtures/item.folder.renamed.json", http.StatusCreated))
+
+	item, _, err := oneDrive.Items.OnConflict(ConflictRename).CreateFolder("root", "Reports")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := item.Name, "Reports 1"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestConflictError(t *testing.T) {
+	tt := []struct {
+		call         func(is *ItemService) error
+		expectedName string
+	}{
+		{func(is *ItemService) error {
+			_, _, err := is.CreateFolder("root", "Reports")
+			return err
+		}, "Reports"},
+		{func(is *ItemService) error {
+			_, _, err := is.Upload("root", "Reports", bytes.NewReader(nil), 0, nil)
+			return err
+		}, "Reports"},
+		{func(is *ItemService) error {
+			_, _, err := is.Move(ItemReference{ID: "123"}, ItemReference{ID: "456"})
+			return err
+		}, ""},
+	}
+	for i, tst := range tt {
+		setup()
+		mux.HandleFunc("/", fileWrapperHandler("fixtures/request.invalid.nameAlreadyExists.json", http.StatusConflict))
+
+		err := tst.call(oneDrive.Items.OnConflict(ConflictFail))
+		var conflictErr *ConflictError
+		if !errors.As(err, &conflictErr) {
+			t.Errorf("[%d] Got %v Expected a *ConflictError", i, err)
+			teardown()
+			continue
+		}
+		if got, want := conflictErr.Name, tst.expectedName; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if !errors.Is(err, ErrNameAlreadyExists) || !errors.Is(err, ErrConflict) {
+			t.Errorf("[%d] Expected %v to match ErrNameAlreadyExists and ErrConflict", i, err)
+		}
+		teardown()
+	}
+}
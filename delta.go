@@ -0,0 +1,230 @@
+package onedrive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/ggordan/go-onedrive/internal/atomicfile"
+)
+
+// deltaPage is a single page of changes returned by view.delta.
+type deltaPage struct {
+	Collection []*Item `json:"value"`
+	NextLink   string  `json:"@odata.nextLink"`
+	DeltaLink  string  `json:"@odata.deltaLink"`
+	DeltaToken string  `json:"@delta.token"`
+}
+
+// token returns the token to resume from once the page has been processed.
+// The legacy API sends it as @delta.token, Graph as the token parameter of
+// @odata.deltaLink.
+func (dp *deltaPage) token() string {
+	if dp.DeltaToken != "" {
+		return dp.DeltaToken
+	}
+	if dp.DeltaLink == "" {
+		return ""
+	}
+	u, err := url.Parse(dp.DeltaLink)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("token")
+}
+
+// DeltaResult holds the changes to a folder hierarchy since a delta token.
+type DeltaResult struct {
+	// Items which were created, modified or deleted. Deleted items have their
+	// Deleted facet set.
+	Items []*Item
+	// Token is the checkpoint to pass to the next call to Delta.
+	Token string
+	// DeltaLink is the URL the service suggested for the next call, if any.
+	DeltaLink string
+}
+
+// deltaURI returns the request URI of the delta function of an item.
+func (is *ItemService) deltaURI(itemID, token string) string {
+	uri := is.itemURI(itemID) + "/view.delta"
+	if is.Graph {
+		uri = is.itemURI(itemID) + "/delta"
+	}
+	if token != "" {
+		uri += "?token=" + url.QueryEscape(token)
+	}
+	return uri
+}
+
+// deltaPages fetches every page of changes to the hierarchy under itemID since
+// token, passing each one to fn, and returns the final page.
+func (is *ItemService) deltaPages(ctx context.Context, itemID, token string, fn func(page *deltaPage) error) (*deltaPage, *http.Response, error) {
+	next := is.deltaURI(itemID, token)
+	for {
+		req, err := is.newRequest(ctx, "GET", next, nil, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		page := new(deltaPage)
+		resp, err := is.do(req, page)
+		if err != nil {
+			return nil, resp, err
+		}
+		if err := fn(page); err != nil {
+			return nil, resp, err
+		}
+
+		if page.NextLink == "" {
+			return page, resp, nil
+		}
+		next = page.NextLink
+	}
+}
+
+// Delta returns the changes to the hierarchy under itemID since token was
+// issued. An empty token enumerates the whole hierarchy. If the token has
+// expired the service requires a full re-enumeration and the returned error
+// matches ErrResyncRequired; call Delta again with an empty token.
+// See: http://onedrive.github.io/items/view_delta.htm
+func (is *ItemService) Delta(itemID, token string) (*DeltaResult, *http.Response, error) {
+	return is.DeltaContext(context.Background(), itemID, token)
+}
+
+// DeltaContext is like Delta but carries a context.
+func (is *ItemService) DeltaContext(ctx context.Context, itemID, token string) (*DeltaResult, *http.Response, error) {
+	result := new(DeltaResult)
+	last, resp, err := is.deltaPages(ctx, itemID, token, func(page *deltaPage) error {
+		result.Items = append(result.Items, page.Collection...)
+		return nil
+	})
+	if err != nil {
+		return nil, resp, err
+	}
+
+	result.Token = last.token()
+	result.DeltaLink = last.DeltaLink
+	return result, resp, nil
+}
+
+// SyncDelta processes the changes to the hierarchy under itemID since the
+// checkpoint held in store, calling fn with each page of changes and saving
+// the new checkpoint once every page has been processed. If the service
+// requires a resync, the checkpoint is discarded and the whole hierarchy is
+// enumerated again with resync set, so fn can reconcile its local state.
+func (is *ItemService) SyncDelta(itemID string, store DeltaTokenStore, fn func(items []*Item, resync bool) error) error {
+	return is.SyncDeltaContext(context.Background(), itemID, store, fn)
+}
+
+// SyncDeltaContext is like SyncDelta but carries a context.
+func (is *ItemService) SyncDeltaContext(ctx context.Context, itemID string, store DeltaTokenStore, fn func(items []*Item, resync bool) error) error {
+	key := is.driveID + "/" + itemID
+	token, err := store.LoadDeltaToken(key)
+	if err != nil {
+		return err
+	}
+
+	resync := false
+	for {
+		last, _, err := is.deltaPages(ctx, itemID, token, func(page *deltaPage) error {
+			return fn(page.Collection, resync)
+		})
+		if errors.Is(err, ErrResyncRequired) && !resync {
+			resync, token = true, ""
+			if err := store.SaveDeltaToken(key, ""); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		return store.SaveDeltaToken(key, last.token())
+	}
+}
+
+// A DeltaTokenStore persists delta tokens so that a process can resume
+// syncing from its last checkpoint. Keys identify the synced hierarchy; a
+// missing key loads as an empty token.
+type DeltaTokenStore interface {
+	LoadDeltaToken(key string) (string, error)
+	SaveDeltaToken(key, token string) error
+}
+
+// MemoryDeltaTokenStore keeps delta tokens in memory. The zero value is ready
+// to use.
+type MemoryDeltaTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// LoadDeltaToken returns the token stored under key.
+func (s *MemoryDeltaTokenStore) LoadDeltaToken(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[key], nil
+}
+
+// SaveDeltaToken stores token under key.
+func (s *MemoryDeltaTokenStore) SaveDeltaToken(key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens == nil {
+		s.tokens = make(map[string]string)
+	}
+	s.tokens[key] = token
+	return nil
+}
+
+// FileDeltaTokenStore keeps delta tokens as a JSON object in a file.
+type FileDeltaTokenStore struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (s *FileDeltaTokenStore) load() (map[string]string, error) {
+	tokens := make(map[string]string)
+	b, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// LoadDeltaToken returns the token stored under key.
+func (s *FileDeltaTokenStore) LoadDeltaToken(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tokens, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	return tokens[key], nil
+}
+
+// SaveDeltaToken stores token under key. The file is replaced atomically.
+func (s *FileDeltaTokenStore) SaveDeltaToken(key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	tokens[key] = token
+
+	b, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.Path, b, 0600)
+}
@@ -0,0 +1,149 @@
+package onedrive
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func deltaHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("token") {
+	case "":
+		fileTemplateHandler("fixtures/item.delta.page1.json", http.StatusOK)(w, r)
+	case "page1-token", "final-token":
+		fileTemplateHandler("fixtures/item.delta.page2.json", http.StatusOK)(w, r)
+	default:
+		fileWrapperHandler("fixtures/request.invalid.resyncRequired.json", http.StatusGone)(w, r)
+	}
+}
+
+func TestDelta(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/root/view.delta", deltaHandler)
+
+	result, _, err := oneDrive.Items.Delta("root", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, item := range result.Items {
+		ids = append(ids, item.ID)
+	}
+	if got, want := ids, []string{"0123456789abc!101", "0123456789abc!104", "0123456789abc!110", "0123456789abc!111"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+	if result.Items[3].Deleted == nil {
+		t.Errorf("Expected %q to be deleted", result.Items[3].Name)
+	}
+	if got, want := result.Token, "final-token"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestDeltaGraph(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive = NewGraphOneDrive(http.DefaultClient, server.URL, false)
+
+	mux.HandleFunc("/me/drive/root/delta", fileTemplateHandler("fixtures/item.delta.graph.json", http.StatusOK))
+
+	result, _, err := oneDrive.Items.Delta("root", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := result.Token, "graph-token"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := result.DeltaLink, server.URL+"/me/drive/root/delta?token=graph-token"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestDeltaResyncRequired(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/root/view.delta", deltaHandler)
+
+	_, _, err := oneDrive.Items.Delta("root", "expired-token")
+	if !errors.Is(err, ErrResyncRequired) {
+		t.Errorf("Got %v Expected %v", err, ErrResyncRequired)
+	}
+}
+
+func TestSyncDelta(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/root/view.delta", deltaHandler)
+
+	store := &FileDeltaTokenStore{Path: filepath.Join(t.TempDir(), "delta.json")}
+	store.SaveDeltaToken("/root", "expired-token")
+
+	var pages []int
+	var resyncs []bool
+	err := oneDrive.Items.SyncDelta("root", store, func(items []*Item, resync bool) error {
+		pages = append(pages, len(items))
+		resyncs = append(resyncs, resync)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pages, []int{2, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+	if got, want := resyncs, []bool{true, true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+
+	token, err := store.LoadDeltaToken("/root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := token, "final-token"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+
+	// The next sync resumes from the saved checkpoint.
+	pages = nil
+	err = oneDrive.Items.SyncDelta("root", store, func(items []*Item, resync bool) error {
+		if resync {
+			t.Error("Expected no resync")
+		}
+		pages = append(pages, len(items))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pages, []int{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+}
+
+func TestSyncDeltaHandlerError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/root/view.delta", deltaHandler)
+
+	store := new(MemoryDeltaTokenStore)
+	stop := errors.New("stop")
+	err := oneDrive.Items.SyncDelta("root", store, func(items []*Item, resync bool) error {
+		return stop
+	})
+	if err != stop {
+		t.Errorf("Got %v Expected %v", err, stop)
+	}
+
+	token, _ := store.LoadDeltaToken("/root")
+	if token != "" {
+		t.Errorf("Expected no checkpoint to be saved, got %q", token)
+	}
+}
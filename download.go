@@ -0,0 +1,317 @@
+package onedrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Download returns the contents of the file with the given itemID. The service
+// redirects downloads to a pre-authenticated URL, which is fetched with the
+// PreauthClient so that the bearer token is not sent to it. The caller must
+// close the returned ReadCloser.
+//
+// If VerifyHashes is set, the hashes of the item are fetched first and the
+// final Read returns an *IntegrityError instead of io.EOF if the content does
+// not match them.
+// See: http://onedrive.github.io/items/download.htm
+func (is *ItemService) Download(itemID string) (io.ReadCloser, *http.Response, error) {
+	return is.DownloadContext(context.Background(), itemID)
+}
+
+// DownloadContext is like Download but carries a context.
+func (is *ItemService) DownloadContext(ctx context.Context, itemID string) (io.ReadCloser, *http.Response, error) {
+	if !is.VerifyHashes {
+		return is.DownloadRangeContext(ctx, itemID, 0, -1)
+	}
+
+	item, resp, err := is.getItem(ctx, is.itemURI(itemID), nil)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	body, resp, err := is.DownloadRangeContext(ctx, itemID, 0, -1)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &verifyingReader{ReadCloser: body, hasher: NewHasher(), expected: itemHashes(item)}, resp, nil
+}
+
+// DownloadRange is like Download but only returns length bytes of the contents
+// starting at offset. A negative length reads to the end of the file.
+func (is *ItemService) DownloadRange(itemID string, offset, length int64) (io.ReadCloser, *http.Response, error) {
+	return is.DownloadRangeContext(context.Background(), itemID, offset, length)
+}
+
+// DownloadRangeContext is like DownloadRange but carries a context.
+func (is *ItemService) DownloadRangeContext(ctx context.Context, itemID string, offset, length int64) (io.ReadCloser, *http.Response, error) {
+	return is.download(ctx, is.itemURI(itemID)+"/content", offset, length)
+}
+
+// DownloadToFile downloads the contents of the file with the given itemID to
+// the local file at filePath. While the download is in progress the version of
+// the item is recorded next to the file, at filePath+".etag". If the local file
+// holds the start of the same version of the contents, as left by an
+// interrupted download, only the remainder is fetched; any other existing file,
+// or any file of an item without a version tag, is downloaded again from the
+// start. If VerifyHashes is set, the complete file is checked against the
+// hashes of the item and removed if it does not match them, so that the next
+// attempt downloads it again.
+func (is *ItemService) DownloadToFile(itemID, filePath string) (*Item, *http.Response, error) {
+	return is.DownloadToFileContext(context.Background(), itemID, filePath)
+}
+
+// DownloadToFileContext is like DownloadToFile but carries a context.
+func (is *ItemService) DownloadToFileContext(ctx context.Context, itemID, filePath string) (*Item, *http.Response, error) {
+	item, resp, err := is.getItem(ctx, is.itemURI(itemID), nil)
+	if err != nil {
+		return nil, resp, err
+	}
+	if item.File == nil {
+		return nil, resp, fmt.Errorf("onedrive: item %s is not a file", itemID)
+	}
+
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, resp, err
+	}
+	defer file.Close()
+
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, resp, err
+	}
+	tagPath := filePath + etagSuffix
+	version := contentTag(item)
+	if offset > 0 {
+		// Only a partial file recorded against the current version of the
+		// item can be resumed, anything else may hold other contents.
+		tag, err := os.ReadFile(tagPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, resp, err
+		}
+		if version == "" || string(tag) != version || offset > item.Size {
+			if offset, err = restart(file); err != nil {
+				return nil, resp, err
+			}
+		}
+	}
+	if offset < item.Size {
+		if err := os.WriteFile(tagPath, []byte(version), 0644); err != nil {
+			return nil, resp, err
+		}
+
+		requestHeaders := make(map[string]string)
+		if offset > 0 {
+			requestHeaders["Range"] = byteRange(offset, -1)
+			// Should the item change after its metadata was fetched, the
+			// service sends the whole of the new contents instead.
+			if item.ETag != "" {
+				requestHeaders["If-Range"] = entityTag(item.ETag)
+			}
+		}
+		bodyResp, err := is.downloadResponse(ctx, is.itemURI(itemID)+"/content", requestHeaders)
+		if err != nil {
+			return nil, bodyResp, err
+		}
+		defer bodyResp.Body.Close()
+
+		resp = bodyResp
+		if resp.StatusCode != http.StatusPartialContent && offset > 0 {
+			if _, err := restart(file); err != nil {
+				return nil, resp, err
+			}
+		}
+		if _, err := io.Copy(file, resp.Body); err != nil {
+			return nil, resp, err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return nil, resp, err
+	}
+
+	if is.VerifyHashes {
+		if err := verifyFile(filePath, item); err != nil {
+			var integrityErr *IntegrityError
+			if errors.As(err, &integrityErr) {
+				// Resuming would find a complete file of the right version
+				// and never fetch it again, so the corrupt file is discarded.
+				if err := os.Remove(filePath); err != nil {
+					return nil, resp, err
+				}
+				if err := os.Remove(tagPath); err != nil && !os.IsNotExist(err) {
+					return nil, resp, err
+				}
+			}
+			return nil, resp, err
+		}
+	}
+
+	if err := os.Remove(tagPath); err != nil && !os.IsNotExist(err) {
+		return nil, resp, err
+	}
+
+	return item, resp, nil
+}
+
+// etagSuffix is appended to the path of a file being downloaded by
+// DownloadToFile to name the file recording the version of its contents.
+const etagSuffix = ".etag"
+
+// contentTag returns the tag identifying the version of the contents of item.
+// The cTag only changes with the contents, unlike the eTag which also changes
+// with the metadata, but it is not reported by every API version.
+func contentTag(item *Item) string {
+	if item.CTag != "" {
+		return item.CTag
+	}
+	return item.ETag
+}
+
+// entityTag returns tag in the quoted form HTTP headers expect it in.
+func entityTag(tag string) string {
+	if strings.HasPrefix(tag, `"`) || strings.HasPrefix(tag, "W/") {
+		return tag
+	}
+	return `"` + tag + `"`
+}
+
+// restart truncates file so that it is written again from the start.
+func restart(file *os.File) (int64, error) {
+	if err := file.Truncate(0); err != nil {
+		return 0, err
+	}
+	return file.Seek(0, io.SeekStart)
+}
+
+// verifyFile checks the local file at filePath against the hashes of item.
+func verifyFile(filePath string, item *Item) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	actual, err := hashReaderAt(file, item.Size)
+	if err != nil {
+		return err
+	}
+	return verifyHashes(itemHashes(item), actual)
+}
+
+// download fetches the content at uri, following the redirect to the
+// pre-authenticated download URL with the PreauthClient.
+func (is *ItemService) download(ctx context.Context, uri string, offset, length int64) (io.ReadCloser, *http.Response, error) {
+	if offset < 0 {
+		return nil, nil, fmt.Errorf("onedrive: negative download offset %d", offset)
+	}
+	if length == 0 {
+		return io.NopCloser(strings.NewReader("")), nil, nil
+	}
+
+	requestHeaders := make(map[string]string)
+	if offset > 0 || length > 0 {
+		requestHeaders["Range"] = byteRange(offset, length)
+	}
+
+	resp, err := is.downloadResponse(ctx, uri, requestHeaders)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	body, err := rangeBody(resp, offset, length)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return body, resp, nil
+}
+
+// downloadResponse requests the content at uri with the given headers,
+// following the redirect to the pre-authenticated download URL with the
+// PreauthClient. The headers are sent with both requests.
+func (is *ItemService) downloadResponse(ctx context.Context, uri string, requestHeaders map[string]string) (*http.Response, error) {
+	req, err := is.newRequest(ctx, "GET", uri, requestHeaders, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := is.doStream(is.noRedirectClient(), req)
+	if err != nil || !isRedirect(resp) {
+		return resp, err
+	}
+
+	resp.Body.Close()
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return resp, err
+	}
+
+	// The download URL is pre-authenticated, so the request is built from
+	// scratch rather than with newRequest and sent without credentials.
+	req, err = http.NewRequestWithContext(ctx, "GET", location.String(), nil)
+	if err != nil {
+		return resp, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	for k, v := range requestHeaders {
+		req.Header.Set(k, v)
+	}
+
+	return is.doStream(is.preauthClient(), req)
+}
+
+// noRedirectClient returns a copy of the client which hands redirects back to
+// the caller instead of following them with the client's credentials.
+func (od *OneDrive) noRedirectClient() *http.Client {
+	return withoutRedirects(od.Client)
+}
+
+// withoutRedirects returns a copy of client which does not follow redirects.
+func withoutRedirects(c *http.Client) *http.Client {
+	client := *c
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &client
+}
+
+// byteRange returns the value of a Range header requesting length bytes from
+// offset, or everything from offset if length is negative.
+func byteRange(offset, length int64) string {
+	if length < 0 {
+		return fmt.Sprintf("bytes=%d-", offset)
+	}
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
+// rangeBody returns the requested range of a download response. Servers which
+// ignore the Range header send the whole content, which is then cut down to
+// the range locally.
+func rangeBody(resp *http.Response, offset, length int64) (io.ReadCloser, error) {
+	if resp.StatusCode == http.StatusPartialContent {
+		return resp.Body, nil
+	}
+
+	if offset > 0 {
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+	if length < 0 {
+		return resp.Body, nil
+	}
+	return readCloser{io.LimitReader(resp.Body, length), resp.Body}, nil
+}
+
+// readCloser combines a Reader with the Closer of the stream it reads from.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
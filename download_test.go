@@ -0,0 +1,288 @@
+package onedrive
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const downloadContent = "abcdefghijklmnopqrstuvwxyz"
+
+// bearerTransport adds a bearer token to every request, as an authenticating
+// client would.
+type bearerTransport struct{}
+
+func (bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer secret")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// downloadETag and downloadCTag are the tags of item 130 in
+// item.download.valid.json.
+const (
+	downloadETag = `"aMDEyMzQ1Njc4OWFiYyExMzAuMg"`
+	downloadCTag = "aYzowMTIzNDU2Nzg5YWJjITEzMC4yNTg"
+)
+
+// downloadHandlers redirects the content of item 130 to a download URL which
+// serves downloadContent, recording the Authorization and Range headers it
+// receives.
+func downloadHandlers(authorization, ranges *[]string) {
+	mux.HandleFunc("/drive/items/130/content", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.Redirect(w, r, server.URL+"/download/130?tempauth=token", http.StatusFound)
+	})
+	mux.HandleFunc("/download/130", func(w http.ResponseWriter, r *http.Request) {
+		*authorization = append(*authorization, r.Header.Get("Authorization"))
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", downloadETag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader([]byte(downloadContent)))
+	})
+}
+
+func TestDownload(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var authorization, ranges []string
+	downloadHandlers(&authorization, &ranges)
+	oneDrive.Client = &http.Client{Transport: bearerTransport{}}
+
+	body, _, err := oneDrive.Items.Download("130")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+
+	b, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), downloadContent; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := authorization, []string{""}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("Expected the download URL to be fetched once without credentials, got %q", got)
+	}
+}
+
+func TestDownloadRange(t *testing.T) {
+	tt := []struct {
+		offset, length int64
+		ignoreRange    bool
+		expectedRange  string
+		expectedOut    string
+	}{
+		{0, 5, false, "bytes=0-4", "abcde"},
+		{20, -1, false, "bytes=20-", "uvwxyz"},
+		{10, 3, false, "bytes=10-12", "klm"},
+		{10, 3, true, "bytes=10-12", "klm"},
+		{23, -1, true, "bytes=23-", "xyz"},
+		{0, -1, false, "", downloadContent},
+	}
+	for i, tst := range tt {
+		setup()
+		var authorization, ranges []string
+		downloadHandlers(&authorization, &ranges)
+		if tst.ignoreRange {
+			mux.HandleFunc("/download/ignored", func(w http.ResponseWriter, r *http.Request) {
+				ranges = append(ranges, r.Header.Get("Range"))
+				w.Write([]byte(downloadContent))
+			})
+			mux.HandleFunc("/drive/items/131/content", func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/download/ignored", http.StatusFound)
+			})
+		}
+
+		itemID := "130"
+		if tst.ignoreRange {
+			itemID = "131"
+		}
+		oneDrive.Client = &http.Client{Transport: bearerTransport{}}
+		body, _, err := oneDrive.Items.DownloadRange(itemID, tst.offset, tst.length)
+		if err != nil {
+			t.Errorf("[%d] %v", i, err)
+			teardown()
+			continue
+		}
+		b, _ := io.ReadAll(body)
+		body.Close()
+		if got, want := string(b), tst.expectedOut; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := ranges, []string{tst.expectedRange}; len(got) != 1 || got[0] != want[0] {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		teardown()
+	}
+}
+
+func TestDownloadNotFound(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/items/404/content", fileWrapperHandler("fixtures/request.invalid.notFound.json", http.StatusNotFound))
+
+	if _, _, err := oneDrive.Items.Download("404"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Got %v Expected %v", err, ErrNotFound)
+	}
+}
+
+func TestDownloadToFile(t *testing.T) {
+	tt := []struct {
+		partial       string
+		tag           string
+		expectedRange []string
+	}{
+		{"", "", []string{""}},
+		{"abcdefghij", downloadCTag, []string{"bytes=10-"}},
+		{"abcdefghij", "", []string{""}},
+		{"abcdefghij", "aYzowMTIzNDU2Nzg5YWJjITEzMC4xMDA", []string{""}},
+		{downloadContent, downloadCTag, nil},
+		{downloadContent, "", []string{""}},
+		{downloadContent + "stale", downloadCTag, []string{""}},
+	}
+	for i, tst := range tt {
+		setup()
+		var authorization, ranges []string
+		downloadHandlers(&authorization, &ranges)
+		mux.HandleFunc("/drive/items/130", fileWrapperHandler("fixtures/item.download.valid.json", http.StatusOK))
+		oneDrive.Client = &http.Client{Transport: bearerTransport{}}
+
+		path := filepath.Join(t.TempDir(), "alphabet.txt")
+		if tst.partial != "" {
+			if err := os.WriteFile(path, []byte(tst.partial), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if tst.tag != "" {
+			if err := os.WriteFile(path+".etag", []byte(tst.tag), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		item, _, err := oneDrive.Items.DownloadToFile("130", path)
+		if err != nil {
+			t.Errorf("[%d] %v", i, err)
+			teardown()
+			continue
+		}
+		if got, want := item.Name, "alphabet.txt"; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(b), downloadContent; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if _, err := os.Stat(path + ".etag"); !os.IsNotExist(err) {
+			t.Errorf("[%d] Expected the version file to be removed, got %v", i, err)
+		}
+		if got, want := len(ranges), len(tst.expectedRange); got != want {
+			t.Errorf("[%d] Got %d Expected %d downloads", i, got, want)
+		} else {
+			for j := range ranges {
+				if got, want := ranges[j], tst.expectedRange[j]; got != want {
+					t.Errorf("[%d] Got %q Expected %q", i, got, want)
+				}
+			}
+		}
+		teardown()
+	}
+}
+
+func TestDownloadToFileChangedDuringResume(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.Client = &http.Client{Transport: bearerTransport{}}
+
+	// The metadata still describes the old version, but by the time the
+	// content is requested the item has changed and has a new eTag.
+	changed := strings.ToUpper(downloadContent)
+	var ifRange string
+	mux.HandleFunc("/drive/items/130", fileWrapperHandler("fixtures/item.download.valid.json", http.StatusOK))
+	mux.HandleFunc("/drive/items/130/content", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, server.URL+"/download/130", http.StatusFound)
+	})
+	mux.HandleFunc("/download/130", func(w http.ResponseWriter, r *http.Request) {
+		ifRange = r.Header.Get("If-Range")
+		w.Header().Set("ETag", `"aMDEyMzQ1Njc4OWFiYyExMzAuMw"`)
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(changed))
+	})
+
+	path := filepath.Join(t.TempDir(), "alphabet.txt")
+	if err := os.WriteFile(path, []byte("abcdefghij"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".etag", []byte(downloadCTag), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := oneDrive.Items.DownloadToFile("130", path); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ifRange, downloadETag; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), changed; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestDownloadToFileWithoutVersion(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.Client = &http.Client{Transport: bearerTransport{}}
+
+	var authorization, ranges []string
+	downloadHandlers(&authorization, &ranges)
+	mux.HandleFunc("/drive/items/131", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "0123456789abc!131", "name": "alphabet.txt", "size": 26, "file": {}}`)
+	})
+	mux.HandleFunc("/drive/items/131/content", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, server.URL+"/download/130", http.StatusFound)
+	})
+
+	// Without a version tag an unrelated file, even with an empty version
+	// file next to it, cannot be told apart from a partial download.
+	path := filepath.Join(t.TempDir(), "alphabet.txt")
+	if err := os.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".etag", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := oneDrive.Items.DownloadToFile("131", path); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ranges, []string{""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), downloadContent; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package onedrive
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
@@ -39,9 +40,12 @@ type Quota struct {
 	State     string `json:"state"`
 }
 
-// Drives represents a collection of Drives
+// Drives represents a collection of Drives. Large collections are split into
+// pages; NextLink is the URL of the next page, see Pager.
 type Drives struct {
 	Collection []*Drive `json:"value"`
+	NextLink   string   `json:"@odata.nextLink"`
+	Count      int64    `json:"@odata.count"`
 }
 
 // The Drive resource represents a drive in OneDrive. It provides information
@@ -85,7 +89,18 @@ func driveChildrenURIFromID(driveID string) string {
 // the users default Drive is returned. A user will always have at least one
 // Drive available -- the default Drive.
 func (ds *DriveService) Get(driveID string) (*Drive, *http.Response, error) {
-	req, err := ds.newRequest("GET", driveURIFromID(driveID), nil, nil)
+	return ds.GetContext(context.Background(), driveID)
+}
+
+// GetContext is like Get but carries a context for cancellation and deadlines
+// and accepts query options.
+func (ds *DriveService) GetContext(ctx context.Context, driveID string, query ...*Query) (*Drive, *http.Response, error) {
+	uri, err := withQuery(driveURIFromID(driveID), driveType, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := ds.newRequest(ctx, "GET", uri, nil, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -101,12 +116,29 @@ func (ds *DriveService) Get(driveID string) (*Drive, *http.Response, error) {
 
 // GetDefault is a convenience function to return the users default Drive
 func (ds *DriveService) GetDefault() (*Drive, *http.Response, error) {
-	return ds.Get("")
+	return ds.GetContext(context.Background(), "")
+}
+
+// GetDefaultContext is like GetDefault but carries a context and accepts
+// query options.
+func (ds *DriveService) GetDefaultContext(ctx context.Context, query ...*Query) (*Drive, *http.Response, error) {
+	return ds.GetContext(ctx, "", query...)
 }
 
 // ListAll returns all the Drives available to the authenticated user
 func (ds *DriveService) ListAll() (*Drives, *http.Response, error) {
-	req, err := ds.newRequest("GET", "/drives", nil, nil)
+	return ds.ListAllContext(context.Background())
+}
+
+// ListAllContext is like ListAll but carries a context and accepts query
+// options.
+func (ds *DriveService) ListAllContext(ctx context.Context, query ...*Query) (*Drives, *http.Response, error) {
+	uri, err := withQuery("/drives", driveType, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := ds.newRequest(ctx, "GET", uri, nil, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -123,7 +155,18 @@ func (ds *DriveService) ListAll() (*Drives, *http.Response, error) {
 // ListChildren returns a collection of all the Items under the Drive root. If no
 // driveID is specified, the children from the root drive are retrieved.
 func (ds *DriveService) ListChildren(driveID string) (*Items, *http.Response, error) {
-	req, err := ds.newRequest("GET", driveChildrenURIFromID(driveID), nil, nil)
+	return ds.ListChildrenContext(context.Background(), driveID)
+}
+
+// ListChildrenContext is like ListChildren but carries a context and accepts
+// query options.
+func (ds *DriveService) ListChildrenContext(ctx context.Context, driveID string, query ...*Query) (*Items, *http.Response, error) {
+	uri, err := withQuery(driveChildrenURIFromID(driveID), itemType, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := ds.newRequest(ctx, "GET", uri, nil, nil)
 	if err != nil {
 		return nil, nil, err
 	}
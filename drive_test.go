@@ -112,19 +112,20 @@ func TestGetMissing(t *testing.T) {
 	}
 
 	expectedErr := &Error{
-		innerError{
+		StatusCode: 404,
+		InnerError: InnerError{
 			Code:    "itemNotFound",
 			Message: "Item Does Not Exist",
-			InnerError: &innerError{
+			InnerError: &InnerError{
 				Code: "itemDoesNotExist",
-				InnerError: &innerError{
+				InnerError: &InnerError{
 					Code: "folderDoesNotExist",
 				},
 			},
 		},
 	}
 
-	if !reflect.DeepEqual(expectedErr, err) {
+	if !equalAPIError(err, expectedErr) {
 		t.Errorf("Got %v Expected %v", err, expectedErr)
 	}
 }
@@ -185,16 +186,17 @@ func TestListAllDrivesInvalid(t *testing.T) {
 	}
 
 	expectedErr := &Error{
-		innerError{
+		StatusCode: 400,
+		InnerError: InnerError{
 			Code:    "invalidArgument",
 			Message: "Bad Argument",
-			InnerError: &innerError{
+			InnerError: &InnerError{
 				Code: "badArgument",
 			},
 		},
 	}
 
-	if !reflect.DeepEqual(err, expectedErr) {
+	if !equalAPIError(err, expectedErr) {
 		t.Errorf("Got %v Expected %v", err, expectedErr)
 	}
 }
@@ -1,26 +1,224 @@
 package onedrive
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
 
 // error types
 
 var (
 	ErrFileTooLarge = errors.New("file is too large for simple upload")
+	ErrHashMismatch = errors.New("content hash mismatch")
+	// ErrUploadSourceChanged is returned by ResumeUpload when the local file
+	// no longer matches the one the saved upload was started with.
+	ErrUploadSourceChanged = errors.New("upload source has changed")
+	// ErrUnknownSize is returned when content of unknown size is too large
+	// to be spooled for an upload session, which needs the total size.
+	ErrUnknownSize = errors.New("upload size is unknown")
+)
+
+// Sentinel errors which an *Error can be matched against with errors.Is. They
+// are matched on either the HTTP status of the response or an error code
+// anywhere in the inner error chain.
+var (
+	ErrBadRequest           = errors.New("bad request")
+	ErrUnauthenticated      = errors.New("unauthenticated")
+	ErrAccessDenied         = errors.New("access denied")
+	ErrNotFound             = errors.New("item not found")
+	ErrConflict             = errors.New("conflict")
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrThrottled            = errors.New("too many requests")
+	ErrServiceUnavailable   = errors.New("service not available")
+	ErrQuotaExceeded        = errors.New("quota exceeded")
+	ErrNameAlreadyExists    = errors.New("name already exists")
+	ErrResyncRequired       = errors.New("resync required")
+	ErrMalwareDetected      = errors.New("malware detected")
+	ErrInvalidRange         = errors.New("invalid range")
+	ErrNotSupported         = errors.New("not supported")
+	ErrActivityLimitReached = errors.New("activity limit reached")
+)
+
+// Error codes returned by the OneDrive API.
+// See: http://onedrive.github.io/misc/errors.htm
+const (
+	CodeAccessDenied         = "accessDenied"
+	CodeActivityLimitReached = "activityLimitReached"
+	CodeGeneralException     = "generalException"
+	CodeInvalidArgument      = "invalidArgument"
+	CodeInvalidRange         = "invalidRange"
+	CodeInvalidRequest       = "invalidRequest"
+	CodeItemNotFound         = "itemNotFound"
+	CodeMalwareDetected      = "malwareDetected"
+	CodeNameAlreadyExists    = "nameAlreadyExists"
+	CodeNotAllowed           = "notAllowed"
+	CodeNotSupported         = "notSupported"
+	CodeQuotaLimitReached    = "quotaLimitReached"
+	CodeResourceModified     = "resourceModified"
+	CodeResyncRequired       = "resyncRequired"
+	CodeServiceNotAvailable  = "serviceNotAvailable"
+	CodeTooManyRequests      = "tooManyRequests"
+	CodeUnauthenticated      = "unauthenticated"
 )
 
-type innerError struct {
+// sentinels maps each sentinel error to the HTTP status and error codes which
+// match it. A zero status means the sentinel is matched on codes alone.
+var sentinels = map[error]struct {
+	status int
+	codes  []string
+}{
+	ErrBadRequest:           {http.StatusBadRequest, []string{CodeInvalidRequest, CodeInvalidArgument}},
+	ErrUnauthenticated:      {http.StatusUnauthorized, []string{CodeUnauthenticated}},
+	ErrAccessDenied:         {http.StatusForbidden, []string{CodeAccessDenied}},
+	ErrNotFound:             {http.StatusNotFound, []string{CodeItemNotFound}},
+	ErrConflict:             {http.StatusConflict, []string{CodeNameAlreadyExists}},
+	ErrPreconditionFailed:   {http.StatusPreconditionFailed, []string{CodeResourceModified}},
+	ErrThrottled:            {statusTooManyRequests, []string{CodeTooManyRequests, CodeActivityLimitReached}},
+	ErrServiceUnavailable:   {http.StatusServiceUnavailable, []string{CodeServiceNotAvailable}},
+	ErrQuotaExceeded:        {statusInsufficientStorage, []string{CodeQuotaLimitReached}},
+	ErrNameAlreadyExists:    {0, []string{CodeNameAlreadyExists}},
+	ErrResyncRequired:       {http.StatusGone, []string{CodeResyncRequired}},
+	ErrMalwareDetected:      {0, []string{CodeMalwareDetected}},
+	ErrInvalidRange:         {http.StatusRequestedRangeNotSatisfiable, []string{CodeInvalidRange}},
+	ErrNotSupported:         {http.StatusNotImplemented, []string{CodeNotSupported}},
+	ErrActivityLimitReached: {0, []string{CodeActivityLimitReached}},
+}
+
+// A ConflictError is returned when an item cannot be created, copied or moved
+// because an item with the same name already exists. It wraps the *Error
+// returned by the service, so it also matches ErrNameAlreadyExists.
+type ConflictError struct {
+	// Name is the name which is already taken, if known.
+	Name string
+	Err  *Error
+}
+
+func (e *ConflictError) Error() string {
+	if e.Name == "" {
+		return "an item with the same name already exists: " + e.Err.Error()
+	}
+	return fmt.Sprintf("an item named %q already exists: %s", e.Name, e.Err.Error())
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// InnerError is a single link in the chain of increasingly specific errors
+// returned by the API.
+type InnerError struct {
 	Code       string      `json:"code"`
 	Message    string      `json:"message"`
-	InnerError *innerError `json:"innererror"`
+	InnerError *InnerError `json:"innererror"`
+	// RequestID is only included by some endpoints, see Error.RequestID.
+	RequestID string `json:"request-id,omitempty"`
 }
 
 // The Error type defines the basic structure of errors that are returned from
 // the OneDrive API.
 // See: http://onedrive.github.io/misc/errors.htm
 type Error struct {
-	innerError `json:"error"`
+	InnerError `json:"error"`
+	// StatusCode is the HTTP status of the response which carried the error.
+	StatusCode int `json:"-"`
+	// RequestID identifies the failed request to Microsoft support.
+	RequestID string `json:"-"`
+	// ContentType, Header and RawBody preserve the response as it was sent,
+	// which helps when the error did not come from the API itself, for
+	// example an HTML page served by a proxy. RawBody holds at most the first
+	// maxErrorBodySize bytes of the body.
+	ContentType string      `json:"-"`
+	Header      http.Header `json:"-"`
+	RawBody     []byte      `json:"-"`
 }
 
 func (e Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
 	return e.Message
 }
+
+// maxErrorBodySize limits how much of an error response body is read.
+const maxErrorBodySize = 16 << 10
+
+// newErrorFromResponse builds an Error from a non-2xx response. The body is
+// decoded as a OneDrive error when possible; otherwise the Error only carries
+// the status and the raw response.
+func newErrorFromResponse(resp *http.Response) *Error {
+	newErr := &Error{
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Header:      resp.Header,
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err == nil && len(body) > 0 {
+		newErr.RawBody = body
+		var decoded Error
+		if json.Unmarshal(body, &decoded) == nil {
+			newErr.InnerError = decoded.InnerError
+		}
+	}
+
+	newErr.RequestID = resp.Header.Get("request-id")
+	if newErr.RequestID == "" {
+		newErr.RequestID = newErr.InnerError.RequestID
+	}
+	return newErr
+}
+
+// Codes returns every error code in the inner error chain, starting with the
+// top-level and least specific code.
+func (e Error) Codes() []string {
+	var codes []string
+	for inner := &e.InnerError; inner != nil; inner = inner.InnerError {
+		if inner.Code != "" {
+			codes = append(codes, inner.Code)
+		}
+	}
+	return codes
+}
+
+// HasCode reports whether code appears anywhere in the inner error chain.
+func (e Error) HasCode(code string) bool {
+	for _, c := range e.Codes() {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Is allows an Error to be matched against the sentinel errors in this
+// package with errors.Is.
+func (e Error) Is(target error) bool {
+	match, ok := sentinels[target]
+	if !ok {
+		return false
+	}
+	if match.status != 0 && e.StatusCode == match.status {
+		return true
+	}
+	for _, code := range match.codes {
+		if e.HasCode(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// Retryable reports whether the request which caused the error may succeed if
+// it is sent again later.
+func (e Error) Retryable() bool {
+	switch e.StatusCode {
+	case statusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return e.HasCode(CodeTooManyRequests) || e.HasCode(CodeActivityLimitReached) ||
+		e.HasCode(CodeServiceNotAvailable)
+}
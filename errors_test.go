@@ -1 +1,171 @@
 package onedrive
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// equalAPIError compares the parts of an API error which are decoded from the
+// response, ignoring the raw response that is preserved alongside them.
+func equalAPIError(err error, expected *Error) bool {
+	apiErr, ok := err.(*Error)
+	if !ok {
+		return false
+	}
+	return apiErr.StatusCode == expected.StatusCode && reflect.DeepEqual(apiErr.InnerError, expected.InnerError)
+}
+
+func TestErrorCodes(t *testing.T) {
+	err := Error{
+		InnerError: InnerError{
+			Code: CodeItemNotFound,
+			InnerError: &InnerError{
+				Code: "itemDoesNotExist",
+				InnerError: &InnerError{
+					Code: "folderDoesNotExist",
+				},
+			},
+		},
+	}
+
+	if got, want := err.Codes(), []string{CodeItemNotFound, "itemDoesNotExist", "folderDoesNotExist"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+	if !err.HasCode("folderDoesNotExist") {
+		t.Errorf("Expected %v to have code %q", err.Codes(), "folderDoesNotExist")
+	}
+	if err.HasCode(CodeAccessDenied) {
+		t.Errorf("Expected %v not to have code %q", err.Codes(), CodeAccessDenied)
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	tt := []struct {
+		err      *Error
+		target   error
+		expected bool
+	}{
+		{&Error{StatusCode: http.StatusNotFound}, ErrNotFound, true},
+		{&Error{InnerError: InnerError{Code: CodeItemNotFound}}, ErrNotFound, true},
+		{&Error{StatusCode: http.StatusNotFound, InnerError: InnerError{Code: CodeItemNotFound}}, ErrAccessDenied, false},
+		{&Error{StatusCode: http.StatusConflict, InnerError: InnerError{Code: CodeNameAlreadyExists}}, ErrConflict, true},
+		{&Error{StatusCode: http.StatusConflict, InnerError: InnerError{Code: CodeNameAlreadyExists}}, ErrNameAlreadyExists, true},
+		{&Error{StatusCode: http.StatusPreconditionFailed}, ErrPreconditionFailed, true},
+		{&Error{StatusCode: http.StatusUnauthorized}, ErrUnauthenticated, true},
+		{&Error{StatusCode: statusInsufficientStorage}, ErrQuotaExceeded, true},
+		{&Error{InnerError: InnerError{Code: "generalException", InnerError: &InnerError{Code: CodeResyncRequired}}}, ErrResyncRequired, true},
+		{&Error{StatusCode: statusTooManyRequests}, ErrThrottled, true},
+		{&Error{StatusCode: http.StatusBadRequest}, ErrFileTooLarge, false},
+	}
+	for i, tst := range tt {
+		if got, want := errors.Is(tst.err, tst.target), tst.expected; got != want {
+			t.Errorf("[%d] Got %t Expected %t", i, got, want)
+		}
+	}
+}
+
+func TestErrorRetryable(t *testing.T) {
+	tt := []struct {
+		err      Error
+		expected bool
+	}{
+		{Error{StatusCode: statusTooManyRequests}, true},
+		{Error{StatusCode: http.StatusServiceUnavailable}, true},
+		{Error{StatusCode: http.StatusGatewayTimeout}, true},
+		{Error{StatusCode: http.StatusBadRequest, InnerError: InnerError{Code: CodeActivityLimitReached}}, true},
+		{Error{StatusCode: http.StatusNotFound}, false},
+		{Error{StatusCode: http.StatusConflict}, false},
+	}
+	for i, tst := range tt {
+		if got, want := tst.err.Retryable(), tst.expected; got != want {
+			t.Errorf("[%d] Got %t Expected %t", i, got, want)
+		}
+	}
+}
+
+func TestErrorAs(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("request-id", "some-request-id")
+		fileWrapperHandler("fixtures/request.invalid.unauthenticated.json", http.StatusUnauthorized)(w, r)
+	})
+
+	_, _, err := oneDrive.Drives.GetDefault()
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Got %T Expected *Error", err)
+	}
+	if got, want := apiErr.StatusCode, http.StatusUnauthorized; got != want {
+		t.Errorf("Got %d Expected %d", got, want)
+	}
+	if got, want := apiErr.RequestID, "some-request-id"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Expected %v to match %v", err, ErrUnauthenticated)
+	}
+}
+
+func TestNonJSONErrorResponses(t *testing.T) {
+	tt := []struct {
+		fixture     string
+		status      int
+		contentType string
+		code        string
+		message     string
+		target      error
+		retryable   bool
+	}{
+		{"fixtures/request.invalid.badGateway.html", http.StatusBadGateway, "text/html", "", "502 Bad Gateway", nil, true},
+		{"fixtures/request.invalid.empty.json", http.StatusUnauthorized, "", "", "401 Unauthorized", ErrUnauthenticated, false},
+		{"fixtures/request.invalid.originError.json", 520, "application/json", CodeGeneralException, "An unspecified error has occurred.", nil, false},
+		{"fixtures/request.invalid.tooManyRequests.json", statusTooManyRequests, "application/json", CodeTooManyRequests, "Too Many Requests", ErrThrottled, true},
+	}
+	for i, tst := range tt {
+		setup()
+		oneDrive.RetryPolicy = nil
+
+		mux.HandleFunc("/drive", func(w http.ResponseWriter, r *http.Request) {
+			if tst.contentType != "" {
+				w.Header().Set("Content-Type", tst.contentType)
+			}
+			fileWrapperHandler(tst.fixture, tst.status)(w, r)
+		})
+		_, _, err := oneDrive.Drives.GetDefault()
+		teardown()
+
+		var apiErr *Error
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("[%d] Got %T (%v) Expected *Error", i, err, err)
+		}
+		if got, want := apiErr.StatusCode, tst.status; got != want {
+			t.Errorf("[%d] Got %d Expected %d", i, got, want)
+		}
+		if got, want := apiErr.ContentType, tst.contentType; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := apiErr.Code, tst.code; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := apiErr.Error(), tst.message; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := apiErr.Retryable(), tst.retryable; got != want {
+			t.Errorf("[%d] Got %t Expected %t", i, got, want)
+		}
+		if tst.target != nil && !errors.Is(err, tst.target) {
+			t.Errorf("[%d] Expected %v to match %v", i, err, tst.target)
+		}
+
+		fixture, _ := ioutil.ReadFile(tst.fixture)
+		if got, want := string(apiErr.RawBody), string(fixture); got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
@@ -8,10 +8,13 @@ import "time"
 type HashesFacet struct {
 	Sha1Hash  string `json:"sha1Hash"`
 	Crc32Hash string `json:"crc32Hash"`
+	// QuickXorHash is reported by OneDrive for Business instead of Sha1Hash.
+	QuickXorHash string `json:"quickXorHash"`
+	Sha256Hash   string `json:"sha256Hash"`
 }
 
 func newHashesFacet(sha1, crc string) *HashesFacet {
-	return &HashesFacet{sha1, crc}
+	return &HashesFacet{Sha1Hash: sha1, Crc32Hash: crc}
 }
 
 // The FileFacet groups file-related data on OneDrive into a single structure.
@@ -140,5 +143,33 @@ type SharingLink struct {
 	Token       string    `json:"token"`
 	WebURL      string    `json:"webUrl"`
 	Type        string    `json:"type"`
+	Scope       string    `json:"scope"`
 	Application *Identity `json:"application"`
+	// WebHTML holds the iframe markup of an embed link.
+	WebHTML string `json:"webHtml"`
+}
+
+// The SearchResultFacet indicates that an item is the result of a search
+// query, and provides information about the result.
+// See: http://onedrive.github.io/facets/searchresult_facet.htm
+type SearchResultFacet struct {
+	OnClickTelemetryURL string `json:"onClickTelemetryUrl"`
+}
+
+// The RemoteItemFacet indicates that an item references an item which lives in
+// another drive, such as an item shared with the user. Use
+// ParentReference.DriveID and ID to address the item itself.
+// See: http://onedrive.github.io/facets/remoteitem_facet.htm
+type RemoteItemFacet struct {
+	ID                   string         `json:"id"`
+	Name                 string         `json:"name"`
+	Size                 int64          `json:"size"`
+	WebURL               string         `json:"webUrl"`
+	CreatedBy            *IdentitySet   `json:"createdBy"`
+	LastModifiedBy       *IdentitySet   `json:"lastModifiedBy"`
+	CreatedDateTime      time.Time      `json:"createdDateTime"`
+	LastModifiedDateTime time.Time      `json:"lastModifiedDateTime"`
+	ParentReference      *ItemReference `json:"parentReference"`
+	File                 *FileFacet     `json:"file"`
+	Folder               *FolderFacet   `json:"folder"`
 }
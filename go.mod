@@ -0,0 +1,3 @@
+module github.com/ggordan/go-onedrive
+
+go 1.23
@@ -0,0 +1,152 @@
+package onedrive
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+// A Hasher computes every content hash the service reports in a HashesFacet
+// in a single pass over the content. Write the content to it, then call
+// Hashes.
+type Hasher struct {
+	sha1     hash.Hash
+	sha256   hash.Hash
+	crc32    hash.Hash32
+	quickXor hash.Hash
+}
+
+// NewHasher returns a Hasher for new content.
+func NewHasher() *Hasher {
+	return &Hasher{
+		sha1:     sha1.New(),
+		sha256:   sha256.New(),
+		crc32:    crc32.NewIEEE(),
+		quickXor: NewQuickXorHash(),
+	}
+}
+
+// Write adds p to the content being hashed. It never returns an error.
+func (h *Hasher) Write(p []byte) (int, error) {
+	h.sha1.Write(p)
+	h.sha256.Write(p)
+	h.crc32.Write(p)
+	h.quickXor.Write(p)
+	return len(p), nil
+}
+
+// Hashes returns the hashes of the content written so far, encoded the way
+// the service reports them: hexadecimal for SHA1, SHA256 and CRC32, base64
+// for QuickXorHash.
+func (h *Hasher) Hashes() *HashesFacet {
+	return &HashesFacet{
+		Sha1Hash:     strings.ToUpper(hex.EncodeToString(h.sha1.Sum(nil))),
+		Sha256Hash:   strings.ToUpper(hex.EncodeToString(h.sha256.Sum(nil))),
+		Crc32Hash:    fmt.Sprintf("%08X", h.crc32.Sum32()),
+		QuickXorHash: base64.StdEncoding.EncodeToString(h.quickXor.Sum(nil)),
+	}
+}
+
+// hashReaderAt returns the hashes of the size bytes of r.
+func hashReaderAt(r io.ReaderAt, size int64) (*HashesFacet, error) {
+	h := NewHasher()
+	if _, err := io.Copy(h, io.NewSectionReader(r, 0, size)); err != nil {
+		return nil, err
+	}
+	return h.Hashes(), nil
+}
+
+// hashReadSeeker returns the hashes of the rest of r, leaving r where it was.
+func hashReadSeeker(r io.ReadSeeker) (*HashesFacet, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	h := NewHasher()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return h.Hashes(), nil
+}
+
+// An IntegrityError is returned when content does not match the hash the
+// service reported for it. It matches ErrHashMismatch.
+type IntegrityError struct {
+	// Algorithm is the hash which was compared: QuickXorHash, SHA256, SHA1
+	// or CRC32.
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("%s: %s is %s, expected %s", ErrHashMismatch, e.Algorithm, e.Actual, e.Expected)
+}
+
+func (e *IntegrityError) Is(target error) bool {
+	return target == ErrHashMismatch
+}
+
+// verifyHashes compares the hashes computed locally with the ones the service
+// reported, using the strongest hash the service reported. Content without
+// reported hashes passes.
+func verifyHashes(expected, actual *HashesFacet) error {
+	if expected == nil {
+		return nil
+	}
+
+	compare := func(algorithm, expected, actual string) error {
+		if expected != actual {
+			return &IntegrityError{Algorithm: algorithm, Expected: expected, Actual: actual}
+		}
+		return nil
+	}
+	switch {
+	case expected.QuickXorHash != "":
+		return compare("QuickXorHash", expected.QuickXorHash, actual.QuickXorHash)
+	case expected.Sha256Hash != "":
+		return compare("SHA256", strings.ToUpper(expected.Sha256Hash), actual.Sha256Hash)
+	case expected.Sha1Hash != "":
+		return compare("SHA1", strings.ToUpper(expected.Sha1Hash), actual.Sha1Hash)
+	case expected.Crc32Hash != "":
+		return compare("CRC32", strings.ToUpper(expected.Crc32Hash), actual.Crc32Hash)
+	}
+	return nil
+}
+
+// itemHashes returns the hashes reported for item, if any.
+func itemHashes(item *Item) *HashesFacet {
+	if item == nil || item.File == nil {
+		return nil
+	}
+	return item.File.Hashes
+}
+
+// verifyingReader checks the content read through it against the hashes
+// reported by the service once it reaches the end.
+type verifyingReader struct {
+	io.ReadCloser
+	hasher   *Hasher
+	expected *HashesFacet
+}
+
+func (vr *verifyingReader) Read(p []byte) (int, error) {
+	n, err := vr.ReadCloser.Read(p)
+	vr.hasher.Write(p[:n])
+	if err == io.EOF {
+		if verr := verifyHashes(vr.expected, vr.hasher.Hashes()); verr != nil {
+			return n, verr
+		}
+	}
+	return n, err
+}
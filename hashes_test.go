@@ -0,0 +1,185 @@
+package onedrive
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHasher(t *testing.T) {
+	tt := []struct {
+		content     string
+		expectedOut HashesFacet
+	}{
+		{"", HashesFacet{
+			Sha1Hash:     "DA39A3EE5E6B4B0D3255BFEF95601890AFD80709",
+			Crc32Hash:    "00000000",
+			QuickXorHash: "AAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+			Sha256Hash:   "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855",
+		}},
+		{"abc", HashesFacet{
+			Sha1Hash:     "A9993E364706816ABA3E25717850C26C9CD0D89D",
+			Crc32Hash:    "352441C2",
+			QuickXorHash: "YRDDGAAAAAAAAAAAAwAAAAAAAAA=",
+			Sha256Hash:   "BA7816BF8F01CFEA414140DE5DAE2223B00361A396177A9CB410FF61F20015AD",
+		}},
+		{"The quick brown fox jumps over the lazy dog", HashesFacet{
+			Sha1Hash:     "2FD4E1C67A2D28FCED849EE1BB76E7391B93EB12",
+			Crc32Hash:    "414FA339",
+			QuickXorHash: "bMSlbysmxJL6S75XwfMcQZOpcr4=",
+			Sha256Hash:   "D7A8FBB307D7809469CA9ABCB0082E4F8D5651E46D3CDB762D02D0BF37C9E592",
+		}},
+		{strings.Repeat("0123456789", 100), HashesFacet{
+			Sha1Hash:     "F2B2F38B074C387A1415C3AFB834C7232F31B097",
+			Crc32Hash:    "7C858FF1",
+			QuickXorHash: "jE6XslXW8BcxmE1Dxhaj4y3tmVM=",
+			Sha256Hash:   "AB6C5F3237F551D208FC2CA5225A4CCA20B3FD638794A804F0ED5549D5041734",
+		}},
+	}
+	for i, tst := range tt {
+		// Write in small pieces to exercise streaming.
+		h := NewHasher()
+		for rest := tst.content; len(rest) > 0; {
+			n := len(rest)
+			if n > 7 {
+				n = 7
+			}
+			h.Write([]byte(rest[:n]))
+			rest = rest[n:]
+		}
+		if got, want := *h.Hashes(), tst.expectedOut; got != want {
+			t.Errorf("[%d] Got %+v Expected %+v", i, got, want)
+		}
+	}
+}
+
+func TestVerifyHashes(t *testing.T) {
+	h := NewHasher()
+	h.Write([]byte("abc"))
+	actual := h.Hashes()
+
+	tt := []struct {
+		expected          *HashesFacet
+		expectedAlgorithm string
+	}{
+		{nil, ""},
+		{&HashesFacet{}, ""},
+		{&HashesFacet{Sha1Hash: "a9993e364706816aba3e25717850c26c9cd0d89d"}, ""},
+		{&HashesFacet{Sha1Hash: "0000000000000000000000000000000000000000"}, "SHA1"},
+		{&HashesFacet{Crc32Hash: "352441c2"}, ""},
+		{&HashesFacet{Crc32Hash: "00000000"}, "CRC32"},
+		{&HashesFacet{Sha256Hash: "00", Sha1Hash: actual.Sha1Hash}, "SHA256"},
+		// The QuickXorHash is preferred and is case sensitive.
+		{&HashesFacet{QuickXorHash: "yrddgaaaaaaaaaaaawaaaaaaaaa=", Sha1Hash: actual.Sha1Hash}, "QuickXorHash"},
+		{&HashesFacet{QuickXorHash: actual.QuickXorHash, Sha1Hash: "00"}, ""},
+	}
+	for i, tst := range tt {
+		err := verifyHashes(tst.expected, actual)
+		if tst.expectedAlgorithm == "" {
+			if err != nil {
+				t.Errorf("[%d] %v", i, err)
+			}
+			continue
+		}
+
+		var integrityErr *IntegrityError
+		if !errors.As(err, &integrityErr) {
+			t.Errorf("[%d] Got %v Expected an *IntegrityError", i, err)
+			continue
+		}
+		if got, want := integrityErr.Algorithm, tst.expectedAlgorithm; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if !errors.Is(err, ErrHashMismatch) {
+			t.Errorf("[%d] Expected %v to match ErrHashMismatch", i, err)
+		}
+	}
+}
+
+func TestDownloadVerifyHashes(t *testing.T) {
+	tt := []struct {
+		fixture     string
+		expectedErr error
+	}{
+		{"fixtures/item.download.valid.json", nil},
+		{"fixtures/item.download.mismatch.json", ErrHashMismatch},
+	}
+	for i, tst := range tt {
+		setup()
+		var authorization, ranges []string
+		downloadHandlers(&authorization, &ranges)
+		mux.HandleFunc("/drive/items/130", fileWrapperHandler(tst.fixture, http.StatusOK))
+		oneDrive.Client = &http.Client{Transport: bearerTransport{}}
+		oneDrive.VerifyHashes = true
+
+		body, _, err := oneDrive.Items.Download("130")
+		if err != nil {
+			t.Fatalf("[%d] %v", i, err)
+		}
+		_, err = io.ReadAll(body)
+		body.Close()
+		if !errors.Is(err, tst.expectedErr) {
+			t.Errorf("[%d] Got %v Expected %v", i, err, tst.expectedErr)
+		}
+
+		// A corrupt file is discarded, so a second attempt downloads it again
+		// rather than finding it complete.
+		path := filepath.Join(t.TempDir(), "alphabet.txt")
+		for attempt := 0; attempt < 2; attempt++ {
+			ranges = nil
+			if _, _, err := oneDrive.Items.DownloadToFile("130", path); !errors.Is(err, tst.expectedErr) {
+				t.Errorf("[%d] Got %v Expected %v", i, err, tst.expectedErr)
+			}
+			if got, want := ranges, []string{""}; tst.expectedErr != nil && !reflect.DeepEqual(got, want) {
+				t.Errorf("[%d] Got %q Expected %q", i, got, want)
+			}
+		}
+		if tst.expectedErr != nil {
+			for _, p := range []string{path, path + ".etag"} {
+				if _, err := os.Stat(p); !os.IsNotExist(err) {
+					t.Errorf("[%d] Expected %s to be removed, got %v", i, p, err)
+				}
+			}
+		}
+		teardown()
+	}
+}
+
+func TestUploadVerifyHashes(t *testing.T) {
+	tt := []struct {
+		content     string
+		expectedErr error
+	}{
+		{downloadContent, nil},
+		{strings.ToUpper(downloadContent), ErrHashMismatch},
+	}
+	for i, tst := range tt {
+		setup()
+		mux.HandleFunc("/drive/items/101/children/alphabet.txt/content", fileWrapperHandler("fixtures/item.download.valid.json", http.StatusCreated))
+		oneDrive.VerifyHashes = true
+
+		r := strings.NewReader(tst.content)
+		if _, _, err := oneDrive.Items.Upload("101", "alphabet.txt", r, -1, nil); !errors.Is(err, tst.expectedErr) {
+			t.Errorf("[%d] Got %v Expected %v", i, err, tst.expectedErr)
+		}
+
+		path := filepath.Join(t.TempDir(), "alphabet.txt")
+		if err := os.WriteFile(path, []byte(tst.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := oneDrive.Items.SimpleUpload("101", file); !errors.Is(err, tst.expectedErr) {
+			t.Errorf("[%d] Got %v Expected %v", i, err, tst.expectedErr)
+		}
+		file.Close()
+		teardown()
+	}
+}
@@ -0,0 +1,39 @@
+// Package atomicfile replaces files atomically, so that readers and crashes
+// never observe a partially written file.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes data to a uniquely named temporary file next to path and
+// renames it into place with the given permissions. Concurrent writers each
+// use their own temporary file, so the last rename wins and path always holds
+// one complete version of the data.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	// Flush the data before the rename makes it visible, otherwise a crash
+	// could leave the renamed file empty.
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
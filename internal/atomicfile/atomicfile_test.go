@@ -0,0 +1,80 @@
+package atomicfile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	if err := WriteFile(path, []byte("first"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("second"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "second"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := info.Mode().Perm(), os.FileMode(0600); got != want {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+}
+
+func TestWriteFileConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	versions := make([][]byte, 8)
+	for i := range versions {
+		versions[i] = bytes.Repeat([]byte{byte('a' + i)}, 64<<10)
+	}
+
+	var wg sync.WaitGroup
+	for _, data := range versions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := WriteFile(path, data, 0600); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	complete := false
+	for _, data := range versions {
+		if bytes.Equal(b, data) {
+			complete = true
+		}
+	}
+	if !complete {
+		t.Errorf("Expected one complete version, got %d bytes starting with %q", len(b), b[:1])
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(entries), 1; got != want {
+		t.Errorf("Got %d Expected %d files, temporary files were left behind", got, want)
+	}
+}
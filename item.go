@@ -1,14 +1,97 @@
 package onedrive
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
-// ItemService manages the communication with Item related API endpoints
+// ItemService manages the communication with Item related API endpoints. It
+// addresses items in the user's default drive; use ForDrive to operate on
+// items in any other drive.
 type ItemService struct {
 	*OneDrive
+	driveID  string
+	conflict ConflictBehaviour
+}
+
+// ForDrive returns an ItemService whose operations address items in the drive
+// with the given ID, such as a shared or business drive discovered through
+// DriveService.ListAll. An empty driveID addresses the default drive.
+func (is *ItemService) ForDrive(driveID string) *ItemService {
+	return &ItemService{OneDrive: is.OneDrive, driveID: driveID, conflict: is.conflict}
+}
+
+// ForItem returns an ItemService addressing the drive which holds item, and
+// the ID of the item within that drive. Items with a RemoteItem facet, such as
+// those returned by SharedWithMe, resolve to the remote item in its owner's
+// drive. An item without a parent reference is assumed to be in the drive
+// addressed by is.
+func (is *ItemService) ForItem(item *Item) (*ItemService, string) {
+	id, parent := item.ID, item.ParentReference
+	if item.RemoteItem != nil {
+		id, parent = item.RemoteItem.ID, item.RemoteItem.ParentReference
+	}
+	if parent == nil || parent.DriveID == "" {
+		return is, id
+	}
+	return is.ForDrive(parent.DriveID), id
+}
+
+// OnConflict returns an ItemService which creates folders, uploads, copies
+// and moves items with the given conflict behaviour, e.g.
+//
+//	item, _, err := od.Items.OnConflict(ConflictRename).CreateFolder("root", "Reports")
+//
+// With ConflictRename the returned item carries the name assigned by the
+// service. With ConflictFail, or the service default, an existing item with
+// the same name causes a *ConflictError.
+func (is *ItemService) OnConflict(behaviour ConflictBehaviour) *ItemService {
+	return &ItemService{OneDrive: is.OneDrive, driveID: is.driveID, conflict: behaviour}
+}
+
+// withConflict appends the conflict behaviour of the ItemService to uri as
+// the instance annotation the API in use expects.
+func (is *ItemService) withConflict(uri string) string {
+	if is.conflict == "" {
+		return uri
+	}
+	sep := "?"
+	if strings.Contains(uri, "?") {
+		sep = "&"
+	}
+	annotation := "@name.conflictBehavior"
+	if is.Graph {
+		annotation = "@microsoft.graph.conflictBehavior"
+	}
+	return uri + sep + annotation + "=" + url.QueryEscape(string(is.conflict))
+}
+
+// conflictError turns a nameAlreadyExists error into a *ConflictError for the
+// item named name.
+func conflictError(err error, name string) error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.HasCode(CodeNameAlreadyExists) {
+		return &ConflictError{Name: name, Err: apiErr}
+	}
+	return err
+}
+
+// DriveID returns the ID of the drive addressed by the ItemService, or an
+// empty string for the default drive.
+func (is *ItemService) DriveID() string {
+	return is.driveID
+}
+
+// itemURI returns the request URI of an item in the drive addressed by the
+// ItemService.
+func (is *ItemService) itemURI(itemID string) string {
+	return driveItemURIFromID(is.driveID, itemID)
 }
 
 // The Thumbnail resource type represents a thumbnail for an image, video,
@@ -32,11 +115,28 @@ type ThumbnailSet struct {
 	Large  *Thumbnail `json:"large"`
 }
 
-// Items represents a collection of Items
+// Items represents a collection of Items. Large collections are split into
+// pages; NextLink is the URL of the next page, see Pager.
 type Items struct {
 	Collection []*Item `json:"value"`
+	NextLink   string  `json:"@odata.nextLink"`
+	Count      int64   `json:"@odata.count"`
 }
 
+// ConflictBehaviour decides what the service does when an item is created
+// with the name of an existing item.
+type ConflictBehaviour string
+
+// Conflict behaviours accepted by the service.
+const (
+	// ConflictFail fails the request with a nameAlreadyExists error.
+	ConflictFail ConflictBehaviour = "fail"
+	// ConflictReplace replaces the existing item.
+	ConflictReplace ConflictBehaviour = "replace"
+	// ConflictRename gives the new item a unique name.
+	ConflictRename ConflictBehaviour = "rename"
+)
+
 // The ItemReference type groups data needed to reference a OneDrive item across
 // the service into a single structure.
 // See: http://onedrive.github.io/resources/itemReference.htm
@@ -52,25 +152,28 @@ type ItemReference struct {
 // the folder or file property, respectively.
 // See: http://onedrive.github.io/resources/item.htm
 type Item struct {
-	ID                   string         `json:"id"`
-	Name                 string         `json:"name"`
-	ETag                 string         `json:"eTag"`
-	CTag                 string         `json:"cTag"`
-	CreatedBy            *IdentitySet   `json:"createdBy"`
-	LastModifiedBy       *IdentitySet   `json:"lastModifiedBy"`
-	CreatedDateTime      time.Time      `json:"createdDateTime"`
-	LastModifiedDateTime time.Time      `json:"lastModifiedDateTime"`
-	Size                 int64          `json:"size"`
-	ParentReference      *ItemReference `json:"parentReference"`
-	WebURL               string         `json:"webUrl"`
-	File                 *FileFacet     `json:"file"`
-	Folder               *FolderFacet   `json:"folder"`
-	Image                *ImageFacet    `json:"image"`
-	Photo                *PhotoFacet    `json:"photo"`
-	Audio                *AudioFacet    `json:"audio"`
-	Video                *VideoFacet    `json:"video"`
-	Location             *LocationFacet `json:"location"`
-	Deleted              *DeletedFacet  `json:"deleted"`
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ETag                 string             `json:"eTag"`
+	CTag                 string             `json:"cTag"`
+	CreatedBy            *IdentitySet       `json:"createdBy"`
+	LastModifiedBy       *IdentitySet       `json:"lastModifiedBy"`
+	CreatedDateTime      time.Time          `json:"createdDateTime"`
+	LastModifiedDateTime time.Time          `json:"lastModifiedDateTime"`
+	Size                 int64              `json:"size"`
+	ParentReference      *ItemReference     `json:"parentReference"`
+	WebURL               string             `json:"webUrl"`
+	File                 *FileFacet         `json:"file"`
+	Folder               *FolderFacet       `json:"folder"`
+	Image                *ImageFacet        `json:"image"`
+	Photo                *PhotoFacet        `json:"photo"`
+	Audio                *AudioFacet        `json:"audio"`
+	Video                *VideoFacet        `json:"video"`
+	Location             *LocationFacet     `json:"location"`
+	Deleted              *DeletedFacet      `json:"deleted"`
+	SearchResult         *SearchResultFacet `json:"searchResult"`
+	RemoteItem           *RemoteItemFacet   `json:"remoteItem"`
+	SpecialFolder        *SpecialFolder     `json:"specialFolder"`
 	// Instance attributes
 	ConflictBehaviour string `json:"@name.conflictBehavior"`
 	DownloadURL       string `json:"@content.downloadUrl"`
@@ -81,99 +184,125 @@ type Item struct {
 	Thumbnails *ThumbnailSet `json:"thumbnails"`
 }
 
-// driveURIFromID returns a valid request URI based on the ID of the drive.
-// Mostly exists to simplify special cases such as the default and root drives.
+// UnmarshalJSON decodes an Item from either API. Microsoft Graph uses its own
+// names for the instance annotations, which are mapped onto the same fields.
+func (i *Item) UnmarshalJSON(b []byte) error {
+	type item Item
+	aux := struct {
+		*item
+		GraphConflictBehaviour string `json:"@microsoft.graph.conflictBehavior"`
+		GraphDownloadURL       string `json:"@microsoft.graph.downloadUrl"`
+		GraphSourceURL         string `json:"@microsoft.graph.sourceUrl"`
+	}{item: (*item)(i)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	if i.ConflictBehaviour == "" {
+		i.ConflictBehaviour = aux.GraphConflictBehaviour
+	}
+	if i.DownloadURL == "" {
+		i.DownloadURL = aux.GraphDownloadURL
+	}
+	if i.SourceURL == "" {
+		i.SourceURL = aux.GraphSourceURL
+	}
+	return nil
+}
+
+// itemURIFromID returns a valid request URI based on the ID of an item in the
+// default drive. Mostly exists to simplify special cases such as the root
+// folder.
 func itemURIFromID(itemID string) string {
+	return driveItemURIFromID("", itemID)
+}
+
+// driveItemURIFromID returns a valid request URI for an item within the drive
+// with the given ID.
+func driveItemURIFromID(driveID, itemID string) string {
 	switch itemID {
 	case "", "root":
-		return "/drive/root"
+		return driveURIFromID(driveID) + "/root"
 	default:
-		return fmt.Sprintf("/drive/items/%s", itemID)
+		return fmt.Sprintf("%s/items/%s", driveURIFromID(driveID), itemID)
 	}
 }
 
 // Get returns an item with the specified ID.
 func (is *ItemService) Get(itemID string) (*Item, *http.Response, error) {
-	req, err := is.newRequest("GET", itemURIFromID(itemID), nil, nil)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	item := new(Item)
-	resp, err := is.do(req, item)
-	if err != nil {
-		return nil, resp, err
-	}
+	return is.GetContext(context.Background(), itemID)
+}
 
-	return item, resp, nil
+// GetContext is like Get but carries a context and accepts query options.
+func (is *ItemService) GetContext(ctx context.Context, itemID string, query ...*Query) (*Item, *http.Response, error) {
+	return is.getItem(ctx, is.itemURI(itemID), query)
 }
 
 // GetDefaultDriveRootFolder is a convenience function to return the root folder
 // of the users default Drive
 func (is *ItemService) GetDefaultDriveRootFolder() (*Item, *http.Response, error) {
-	return is.Get("root")
+	return is.GetContext(context.Background(), "root")
+}
+
+// GetDefaultDriveRootFolderContext is like GetDefaultDriveRootFolder but
+// carries a context and accepts query options.
+func (is *ItemService) GetDefaultDriveRootFolderContext(ctx context.Context, query ...*Query) (*Item, *http.Response, error) {
+	return is.GetContext(ctx, "root", query...)
 }
 
 // ListChildren returns a collection of all the Items under an Item
 func (is *ItemService) ListChildren(itemID string) (*Items, *http.Response, error) {
-	reqURI := fmt.Sprintf("/drive/items/%s/children", itemID)
-	req, err := is.newRequest("GET", reqURI, nil, nil)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	items := new(Items)
-	resp, err := is.do(req, items)
-	if err != nil {
-		return nil, resp, err
-	}
+	return is.ListChildrenContext(context.Background(), itemID)
+}
 
-	return items, resp, nil
+// ListChildrenContext is like ListChildren but carries a context and accepts
+// query options.
+func (is *ItemService) ListChildrenContext(ctx context.Context, itemID string, query ...*Query) (*Items, *http.Response, error) {
+	return is.listChildren(ctx, is.itemURI(itemID), query)
 }
 
 type newFolder struct {
-	Name   string       `json:"name"`
-	Folder *FolderFacet `json:"folder"`
+	Name                   string            `json:"name"`
+	Folder                 *FolderFacet      `json:"folder"`
+	ConflictBehaviour      ConflictBehaviour `json:"@name.conflictBehavior,omitempty"`
+	GraphConflictBehaviour ConflictBehaviour `json:"@microsoft.graph.conflictBehavior,omitempty"`
 }
 
 // CreateFolder creates a new folder within the parent.
 func (is *ItemService) CreateFolder(parentID, folderName string) (*Item, *http.Response, error) {
-	folder := newFolder{
-		Name:   folderName,
-		Folder: new(FolderFacet),
-	}
-
-	path := fmt.Sprintf("/drive/items/%s/children/%s", parentID, folderName)
-	req, err := is.newRequest("PUT", path, nil, folder)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	item := new(Item)
-	resp, err := is.do(req, item)
-	if err != nil {
-		return nil, resp, err
-	}
+	return is.CreateFolderContext(context.Background(), parentID, folderName)
+}
 
-	return item, resp, nil
+// CreateFolderContext is like CreateFolder but carries a context.
+func (is *ItemService) CreateFolderContext(ctx context.Context, parentID, folderName string) (*Item, *http.Response, error) {
+	return is.createFolder(ctx, is.itemURI(parentID), folderName)
 }
 
 type newWebUpload struct {
-	SourceURL string     `json:"@content.sourceUrl"`
-	Name      string     `json:"name"`
-	File      *FileFacet `json:"file"`
+	SourceURL              string            `json:"@content.sourceUrl,omitempty"`
+	GraphSourceURL         string            `json:"@microsoft.graph.sourceUrl,omitempty"`
+	Name                   string            `json:"name"`
+	File                   *FileFacet        `json:"file"`
+	ConflictBehaviour      ConflictBehaviour `json:"@name.conflictBehavior,omitempty"`
+	GraphConflictBehaviour ConflictBehaviour `json:"@microsoft.graph.conflictBehavior,omitempty"`
 }
 
-// Move changes the parent folder for a OneDrive Item resource.
-// See: http://onedrive.github.io/items/move.htm
+// Update updates the metadata of a OneDrive Item resource. If ifMatch is true
+// the request only succeeds when the item's eTag still matches.
+// See: http://onedrive.github.io/items/update.htm
 func (is ItemService) Update(item *Item, ifMatch bool) (*Item, *http.Response, error) {
+	return is.UpdateContext(context.Background(), item, ifMatch)
+}
+
+// UpdateContext is like Update but carries a context.
+func (is ItemService) UpdateContext(ctx context.Context, item *Item, ifMatch bool) (*Item, *http.Response, error) {
 	requestHeaders := make(map[string]string)
 	if ifMatch {
 		requestHeaders["if-match"] = item.ETag
 	}
 
-	path := fmt.Sprintf("/drive/items/%s", item.ID)
-	req, err := is.newRequest("PATCH", path, requestHeaders, item)
+	path := is.itemURI(item.ID)
+	req, err := is.newRequest(ctx, "PATCH", path, requestHeaders, item)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -191,30 +320,34 @@ func (is ItemService) Update(item *Item, ifMatch bool) (*Item, *http.Response, e
 // permanently deleting them.
 // See: http://onedrive.github.io/items/delete.htm
 func (is *ItemService) Delete(itemID, eTag string) (bool, *http.Response, error) {
-	requestHeaders := make(map[string]string)
-	if eTag != "" {
-		requestHeaders["if-match"] = eTag
-	}
-
-	path := fmt.Sprintf("/drive/items/%s", itemID)
-	req, err := is.newRequest("DELETE", path, requestHeaders, nil)
-	if err != nil {
-		return false, nil, err
-	}
-
-	resp, err := is.do(req, nil)
-	if err != nil {
-		return false, resp, err
-	}
+	return is.DeleteContext(context.Background(), itemID, eTag)
+}
 
-	return (resp.StatusCode == statusNoContent), resp, err
+// DeleteContext is like Delete but carries a context.
+func (is *ItemService) DeleteContext(ctx context.Context, itemID, eTag string) (bool, *http.Response, error) {
+	return is.deleteItem(ctx, is.itemURI(itemID), eTag)
 }
 
-// Move changes the parent folder for a OneDrive Item resource.
+// Move changes the parent folder for a OneDrive Item resource. The item is
+// located by itemID.ID, within itemID.DriveID if set or the drive addressed by
+// the ItemService otherwise.
 // See: http://onedrive.github.io/items/move.htm
 func (is ItemService) Move(itemID, parentReference ItemReference) (*Item, *http.Response, error) {
-	path := fmt.Sprintf("/drive/items/%s", itemID)
-	req, err := is.newRequest("PATCH", path, nil, parentReference)
+	return is.MoveContext(context.Background(), itemID, parentReference)
+}
+
+// MoveContext is like Move but carries a context.
+func (is ItemService) MoveContext(ctx context.Context, itemID, parentReference ItemReference) (*Item, *http.Response, error) {
+	driveID := itemID.DriveID
+	if driveID == "" {
+		driveID = is.driveID
+	}
+	move := struct {
+		ParentReference *ItemReference `json:"parentReference"`
+	}{&parentReference}
+
+	path := is.withConflict(driveItemURIFromID(driveID, itemID.ID))
+	req, err := is.newRequest(ctx, "PATCH", path, nil, move)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -222,15 +355,22 @@ func (is ItemService) Move(itemID, parentReference ItemReference) (*Item, *http.
 	item := new(Item)
 	resp, err := is.do(req, item)
 	if err != nil {
-		return nil, resp, err
+		return nil, resp, conflictError(err, "")
 	}
 
 	return item, resp, nil
 }
 
-// Move changes the parent folder for a OneDrive Item resource.
-// See: http://onedrive.github.io/items/move.htm
-func (is ItemService) Copy(itemID, name string, parentReference ItemReference) (*Item, *http.Response, error) {
+// Copy creates a copy of an item, including any children, under a new parent.
+// The copy happens asynchronously, the returned AsyncJob can be used to track
+// its progress and to Wait for the copied item.
+// See: http://onedrive.github.io/items/copy.htm
+func (is ItemService) Copy(itemID, name string, parentReference ItemReference) (*AsyncJob, *http.Response, error) {
+	return is.CopyContext(context.Background(), itemID, name, parentReference)
+}
+
+// CopyContext is like Copy but carries a context.
+func (is ItemService) CopyContext(ctx context.Context, itemID, name string, parentReference ItemReference) (*AsyncJob, *http.Response, error) {
 	copyAction := struct {
 		ParentReference *ItemReference `json:"parentReference"`
 		Name            string         `json:"name,omitempty"`
@@ -239,8 +379,41 @@ func (is ItemService) Copy(itemID, name string, parentReference ItemReference) (
 	// The copy action requires a Prefer: respond-async header
 	headers := map[string]string{"Prefer": "respond-async"}
 
-	path := fmt.Sprintf("/drive/items/%s/action.copy", itemID)
-	req, err := is.newRequest("POST", path, headers, copyAction)
+	action := "/action.copy"
+	if is.Graph {
+		action = "/copy"
+	}
+	path := is.withConflict(is.itemURI(itemID) + action)
+	req, err := is.newRequest(ctx, "POST", path, headers, copyAction)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := is.do(req, nil)
+	if err != nil {
+		return nil, resp, conflictError(err, name)
+	}
+
+	driveID := parentReference.DriveID
+	if driveID == "" {
+		driveID = is.driveID
+	}
+	job, err := is.newAsyncJob(resp, driveID)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return job, resp, nil
+}
+
+// getItem fetches the item at the given request URI.
+func (is *ItemService) getItem(ctx context.Context, uri string, query []*Query) (*Item, *http.Response, error) {
+	uri, err := withQuery(uri, itemType, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := is.newRequest(ctx, "GET", uri, nil, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -253,3 +426,76 @@ func (is ItemService) Copy(itemID, name string, parentReference ItemReference) (
 
 	return item, resp, nil
 }
+
+// listChildren fetches the children of the item at the given request URI.
+func (is *ItemService) listChildren(ctx context.Context, uri string, query []*Query) (*Items, *http.Response, error) {
+	uri, err := withQuery(uri+"/children", itemType, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := is.newRequest(ctx, "GET", uri, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := new(Items)
+	resp, err := is.do(req, items)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return items, resp, nil
+}
+
+// createFolder creates a folder named folderName within the item at the given
+// request URI.
+func (is *ItemService) createFolder(ctx context.Context, parentURI, folderName string) (*Item, *http.Response, error) {
+	folder := newFolder{
+		Name:   folderName,
+		Folder: new(FolderFacet),
+	}
+
+	// The legacy API creates folders by PUTting them at their path, Graph
+	// only accepts a POST to the parent's children with the name in the body.
+	method, path := "PUT", fmt.Sprintf("%s/children/%s", parentURI, url.PathEscape(folderName))
+	if is.Graph {
+		folder.GraphConflictBehaviour = is.conflict
+		method, path = "POST", parentURI+"/children"
+	} else {
+		folder.ConflictBehaviour = is.conflict
+	}
+
+	req, err := is.newRequest(ctx, method, path, nil, folder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	item := new(Item)
+	resp, err := is.do(req, item)
+	if err != nil {
+		return nil, resp, conflictError(err, folderName)
+	}
+
+	return item, resp, nil
+}
+
+// deleteItem deletes the item at the given request URI.
+func (is *ItemService) deleteItem(ctx context.Context, uri, eTag string) (bool, *http.Response, error) {
+	requestHeaders := make(map[string]string)
+	if eTag != "" {
+		requestHeaders["if-match"] = eTag
+	}
+
+	req, err := is.newRequest(ctx, "DELETE", uri, requestHeaders, nil)
+	if err != nil {
+		return false, nil, err
+	}
+
+	resp, err := is.do(req, nil)
+	if err != nil {
+		return false, resp, err
+	}
+
+	return (resp.StatusCode == statusNoContent), resp, err
+}
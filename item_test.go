@@ -1,6 +1,7 @@
 package onedrive
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -200,19 +201,20 @@ func TestGetItemInvalid(t *testing.T) {
 	}
 
 	expectedErr := &Error{
-		innerError{
+		StatusCode: 404,
+		InnerError: InnerError{
 			Code:    "itemNotFound",
 			Message: "Item Does Not Exist",
-			InnerError: &innerError{
+			InnerError: &InnerError{
 				Code: "itemDoesNotExist",
-				InnerError: &innerError{
+				InnerError: &InnerError{
 					Code: "folderDoesNotExist",
 				},
 			},
 		},
 	}
 
-	if !reflect.DeepEqual(expectedErr, err) {
+	if !equalAPIError(err, expectedErr) {
 		t.Errorf("Got %v Expected %v", err, expectedErr)
 	}
 }
@@ -293,7 +295,139 @@ func TestCreateFolder(t *testing.T) {
 	}
 }
 
+func TestCreateFolderGraph(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.Graph = true
+
+	var method string
+	var body map[string]interface{}
+	mux.HandleFunc("/me/drive/items/0123456789abc!104/children", func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		fileWrapperHandler("fixtures/item.folder.valid.json", http.StatusCreated)(w, r)
+	})
+
+	if _, _, err := oneDrive.Items.CreateFolder("0123456789abc!104", "Test folder 1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := method, "POST"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := body["name"], "Test folder 1"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if _, ok := body["folder"]; !ok {
+		t.Errorf("Expected a folder facet in %v", body)
+	}
+}
+
 func TestDeleteItem(t *testing.T) {
 	setup()
 	defer teardown()
 }
+
+func TestDriveItemURIFromID(t *testing.T) {
+	tt := []struct {
+		driveID, itemID, out string
+	}{
+		{"", "root", "/drive/root"},
+		{"", "123", "/drive/items/123"},
+		{"b!business", "", "/drives/b!business/root"},
+		{"b!business", "123", "/drives/b!business/items/123"},
+	}
+	for i, tst := range tt {
+		if got, want := driveItemURIFromID(tst.driveID, tst.itemID), tst.out; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestForDrive(t *testing.T) {
+	tt := []struct {
+		driveID string
+		call    func(is *ItemService) error
+		method  string
+		path    string
+	}{
+		{"0123456789abc", func(is *ItemService) error {
+			_, _, err := is.Get("shared-id")
+			return err
+		}, "GET", "/drives/0123456789abc/items/shared-id"},
+		{"b!business", func(is *ItemService) error {
+			_, _, err := is.ListChildren("folder-id")
+			return err
+		}, "GET", "/drives/b!business/items/folder-id/children"},
+		{"b!business", func(is *ItemService) error {
+			_, _, err := is.CreateFolder("folder-id", "new")
+			return err
+		}, "PUT", "/drives/b!business/items/folder-id/children/new"},
+		{"b!business", func(is *ItemService) error {
+			_, _, err := is.Delete("file-id", "")
+			return err
+		}, "DELETE", "/drives/b!business/items/file-id"},
+		{"b!business", func(is *ItemService) error {
+			_, _, err := is.Copy("file-id", "copy", ItemReference{ID: "folder-id"})
+			return err
+		}, "POST", "/drives/b!business/items/file-id/action.copy"},
+		{"b!business", func(is *ItemService) error {
+			_, _, err := is.UploadFromURL("folder-id", "file", "http://example.com/file")
+			return err
+		}, "POST", "/drives/b!business/items/folder-id/children"},
+		{"b!business", func(is *ItemService) error {
+			_, _, err := is.Move(ItemReference{ID: "file-id"}, ItemReference{ID: "folder-id"})
+			return err
+		}, "PATCH", "/drives/b!business/items/file-id"},
+		{"", func(is *ItemService) error {
+			_, _, err := is.Move(ItemReference{DriveID: "0123456789abc", ID: "file-id"}, ItemReference{ID: "folder-id"})
+			return err
+		}, "PATCH", "/drives/0123456789abc/items/file-id"},
+	}
+	for i, tst := range tt {
+		setup()
+		var method, path string
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			method, path = r.Method, r.URL.Path
+			w.Header().Set("Location", "/monitor/job")
+			fileWrapperHandler("fixtures/item.folder.valid.json", http.StatusOK)(w, r)
+		})
+
+		is := oneDrive.Items.ForDrive(tst.driveID)
+		if got, want := is.DriveID(), tst.driveID; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if err := tst.call(is); err != nil {
+			t.Errorf("[%d] %s", i, err)
+		}
+		teardown()
+
+		if got, want := method, tst.method; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := path, tst.path; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestMoveItem(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var body map[string]*ItemReference
+	mux.HandleFunc("/drive/items/file-id", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			panic(err)
+		}
+		fileWrapperHandler("fixtures/item.image.valid.json", http.StatusOK)(w, r)
+	})
+
+	if _, _, err := oneDrive.Items.Move(ItemReference{ID: "file-id"}, ItemReference{ID: "folder-id"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := body["parentReference"], (&ItemReference{ID: "folder-id"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+}
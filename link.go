@@ -0,0 +1,132 @@
+package onedrive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// LinkType is the kind of access a sharing link grants.
+type LinkType string
+
+const (
+	// LinkView creates a read-only link.
+	LinkView LinkType = "view"
+	// LinkEdit creates a read-write link.
+	LinkEdit LinkType = "edit"
+	// LinkEmbed creates a read-only link which can be embedded in a web page.
+	// It is only available on OneDrive personal.
+	LinkEmbed LinkType = "embed"
+)
+
+// LinkScope is the set of people a sharing link works for. An empty scope
+// leaves the choice to the service's default for the drive.
+type LinkScope string
+
+const (
+	// LinkScopeAnonymous links work for anyone who has the link.
+	LinkScopeAnonymous LinkScope = "anonymous"
+	// LinkScopeOrganization links work for anyone signed in to the owner's
+	// organization. They are only available on OneDrive for Business.
+	LinkScopeOrganization LinkScope = "organization"
+)
+
+// LinkOptions are the optional settings of a new sharing link. They require
+// the Graph API.
+type LinkOptions struct {
+	// ExpirationDateTime is when the link stops working. The zero value
+	// creates a link which does not expire.
+	ExpirationDateTime time.Time
+	// Password is required to open the link. Like ExpirationDateTime it
+	// needs the Graph API, and CreateLink fails with ErrNotSupported
+	// without it. Even there, the service only honours passwords for
+	// OneDrive personal accounts.
+	Password string
+}
+
+// CreateLink creates a sharing link for the item, or returns the existing
+// link with the same type and scope. The permission returned carries the link
+// in its Link field.
+// See: http://onedrive.github.io/items/sharing_createLink.htm
+func (is *ItemService) CreateLink(itemID string, linkType LinkType, scope LinkScope, opts *LinkOptions) (*Permission, *http.Response, error) {
+	return is.CreateLinkContext(context.Background(), itemID, linkType, scope, opts)
+}
+
+// CreateLinkContext is like CreateLink but carries a context.
+func (is *ItemService) CreateLinkContext(ctx context.Context, itemID string, linkType LinkType, scope LinkScope, opts *LinkOptions) (*Permission, *http.Response, error) {
+	createLink := struct {
+		Type               LinkType  `json:"type"`
+		Scope              LinkScope `json:"scope,omitempty"`
+		ExpirationDateTime string    `json:"expirationDateTime,omitempty"`
+		Password           string    `json:"password,omitempty"`
+	}{Type: linkType, Scope: scope}
+
+	if opts != nil {
+		if !is.Graph && (!opts.ExpirationDateTime.IsZero() || opts.Password != "") {
+			return nil, nil, fmt.Errorf("link expiration and passwords require the Graph API: %w", ErrNotSupported)
+		}
+		if !opts.ExpirationDateTime.IsZero() {
+			createLink.ExpirationDateTime = opts.ExpirationDateTime.UTC().Format(time.RFC3339)
+		}
+		createLink.Password = opts.Password
+	}
+
+	action := "/action.createLink"
+	if is.Graph {
+		action = "/createLink"
+	}
+	req, err := is.newRequest(ctx, "POST", is.itemURI(itemID)+action, nil, createLink)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permission := new(Permission)
+	resp, err := is.do(req, permission)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return permission, resp, nil
+}
+
+// ListLinks returns the sharing links of the item, including those inherited
+// from its ancestors. Permissions granted directly to users are left out.
+func (is *ItemService) ListLinks(itemID string) ([]*Permission, *http.Response, error) {
+	return is.ListLinksContext(context.Background(), itemID)
+}
+
+// ListLinksContext is like ListLinks but carries a context.
+func (is *ItemService) ListLinksContext(ctx context.Context, itemID string) ([]*Permission, *http.Response, error) {
+	permissions, resp, err := is.permissions().ListContext(ctx, itemID)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	var links []*Permission
+	for _, permission := range permissions.Collection {
+		if permission.Link != nil {
+			links = append(links, permission)
+		}
+	}
+
+	return links, resp, nil
+}
+
+// RevokeLink deletes the sharing link with the given permission ID, after
+// which the link no longer grants access to the item. Links inherited from an
+// ancestor have to be revoked on that ancestor.
+func (is *ItemService) RevokeLink(itemID, permissionID string) (*http.Response, error) {
+	return is.RevokeLinkContext(context.Background(), itemID, permissionID)
+}
+
+// RevokeLinkContext is like RevokeLink but carries a context.
+func (is *ItemService) RevokeLinkContext(ctx context.Context, itemID, permissionID string) (*http.Response, error) {
+	return is.permissions().DeleteContext(ctx, itemID, permissionID)
+}
+
+// permissions returns a PermissionService for the drive addressed by the
+// ItemService.
+func (is *ItemService) permissions() *PermissionService {
+	return &PermissionService{OneDrive: is.OneDrive, driveID: is.driveID}
+}
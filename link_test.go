@@ -0,0 +1,126 @@
+package onedrive
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCreateLink(t *testing.T) {
+	expiry := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	tt := []struct {
+		graph        bool
+		linkType     LinkType
+		scope        LinkScope
+		opts         *LinkOptions
+		expectedPath string
+		expectedBody map[string]string
+	}{
+		{false, LinkView, "", nil, "/drive/items/some-id/action.createLink", map[string]string{"type": "view"}},
+		{false, LinkEdit, LinkScopeOrganization, nil, "/drive/items/some-id/action.createLink", map[string]string{"type": "edit", "scope": "organization"}},
+		{true, LinkEdit, LinkScopeAnonymous, &LinkOptions{ExpirationDateTime: expiry, Password: "secret"}, "/me/drive/items/some-id/createLink", map[string]string{
+			"type":               "edit",
+			"scope":              "anonymous",
+			"expirationDateTime": "2099-01-01T00:00:00Z",
+			"password":           "secret",
+		}},
+	}
+	for i, tst := range tt {
+		setup()
+		oneDrive.Graph = tst.graph
+		var method, path string
+		var body map[string]string
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			method, path = r.Method, r.URL.Path
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("[%d] %s", i, err)
+			}
+			fileWrapperHandler("fixtures/permission.link.json", http.StatusCreated)(w, r)
+		})
+
+		permission, _, err := oneDrive.Items.CreateLink("some-id", tst.linkType, tst.scope, tst.opts)
+		teardown()
+		if err != nil {
+			t.Errorf("[%d] %s", i, err)
+			continue
+		}
+		if got, want := method, "POST"; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := path, tst.expectedPath; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := len(body), len(tst.expectedBody); got != want {
+			t.Errorf("[%d] Got %v Expected %v", i, body, tst.expectedBody)
+		}
+		for k, want := range tst.expectedBody {
+			if got := body[k]; got != want {
+				t.Errorf("[%d] Got %q Expected %q for %s", i, got, want, k)
+			}
+		}
+		if got, want := permission.Link.WebURL, "https://1drv.ms/A6913278E564460AA616C71B28AD6EB6"; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := permission.ExpirationDateTime, expiry; !got.Equal(want) {
+			t.Errorf("[%d] Got %v Expected %v", i, got, want)
+		}
+	}
+}
+
+func TestCreateLinkOptionsRequireGraph(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, _, err := oneDrive.Items.CreateLink("some-id", LinkView, "", &LinkOptions{Password: "secret"})
+	if !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Got %v Expected %v", err, ErrNotSupported)
+	}
+}
+
+func TestListLinks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drives/b!business/items/some-id/permissions", fileWrapperHandler("fixtures/permission.collection.json", http.StatusOK))
+
+	links, _, err := oneDrive.Items.ForDrive("b!business").ListLinks("some-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(links), 2; got != want {
+		t.Fatalf("Got %d Expected %d links", got, want)
+	}
+	if got, want := links[0].InheritedFrom.ID, "0123456789abc!100"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := links[1].Link.Type, string(LinkEmbed); got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if links[1].Link.WebHTML == "" {
+		t.Error("Expected the embed link to carry its iframe markup")
+	}
+}
+
+func TestRevokeLink(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var method string
+	mux.HandleFunc("/drive/items/some-id/permissions/123ABC", func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	resp, err := oneDrive.Items.RevokeLink("some-id", "123ABC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := method, "DELETE"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := resp.StatusCode, http.StatusNoContent; got != want {
+		t.Errorf("Got %d Expected %d", got, want)
+	}
+}
@@ -2,6 +2,8 @@ package onedrive
 
 import (
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -11,17 +13,53 @@ const (
 	userAgent = "github.com/ggordan/go-onedrive; version " + version
 )
 
+// Base URLs of Microsoft Graph in each of the national clouds, for use with
+// NewGraphOneDrive.
+// See: https://docs.microsoft.com/graph/deployments
+const (
+	GraphGlobal   = "https://graph.microsoft.com/v1.0"
+	GraphUSGov    = "https://graph.microsoft.us/v1.0"
+	GraphUSGovDoD = "https://dod-graph.microsoft.us/v1.0"
+	GraphChina    = "https://microsoftgraph.chinacloudapi.cn/v1.0"
+	GraphGermany  = "https://graph.microsoft.de/v1.0"
+)
+
 // OneDrive is the entry point for the client. It manages the communication with
 // Microsoft OneDrive API
+//
+// A OneDrive client is safe for concurrent use by multiple goroutines. The
+// exported configuration fields should be set before the client is shared;
+// use SetDebug to toggle debugging while requests are in flight.
 type OneDrive struct {
 	Client *http.Client
 	// When debug is set to true, the JSON response is formatted for better readability
 	Debug   bool
 	BaseURL string
+	// Graph switches path building and response handling from the legacy
+	// OneDrive API to Microsoft Graph.
+	Graph bool
+	// DriveOwner is the Graph resource whose drives are addressed by the
+	// default drive paths, e.g. "/me" (used when empty), "/users/{id}",
+	// "/groups/{id}" or "/sites/{id}". It is ignored by the legacy API.
+	DriveOwner string
+	// RetryPolicy controls how transient failures are retried. A nil policy
+	// disables retries.
+	RetryPolicy *RetryPolicy
+	// PreauthClient is used for the pre-authenticated URLs the service hands
+	// out for downloads and upload sessions. Those URLs must not receive the
+	// bearer token, so it should not be a client which adds one; when nil,
+	// http.DefaultClient is used.
+	PreauthClient *http.Client
+	// VerifyHashes checks uploaded and downloaded content against the hashes
+	// the service reports for it. A mismatch fails with an *IntegrityError.
+	VerifyHashes bool
 	// Services
-	Drives *DriveService
-	Items  *ItemService
+	Drives      *DriveService
+	Items       *ItemService
+	Permissions *PermissionService
+	Shares      *SharesService
 	// Private
+	mu       sync.RWMutex
 	throttle time.Time
 }
 
@@ -29,16 +67,88 @@ type OneDrive struct {
 // the API
 func NewOneDrive(c *http.Client, debug bool) *OneDrive {
 	drive := OneDrive{
-		Client:   c,
-		BaseURL:  baseURL,
-		Debug:    debug,
-		throttle: time.Now(),
+		Client:      c,
+		BaseURL:     baseURL,
+		Debug:       debug,
+		RetryPolicy: DefaultRetryPolicy(),
+		throttle:    time.Now(),
 	}
 	drive.Drives = &DriveService{&drive}
-	drive.Items = &ItemService{&drive}
+	drive.Items = &ItemService{OneDrive: &drive}
+	drive.Permissions = &PermissionService{OneDrive: &drive}
+	drive.Shares = &SharesService{&drive}
 	return &drive
 }
 
-func (od *OneDrive) throttleRequest(time time.Time) {
-	od.throttle = time
+// NewGraphOneDrive returns a new OneDrive client which talks to Microsoft
+// Graph at baseURL, one of GraphGlobal, GraphUSGov, GraphUSGovDoD, GraphChina
+// or GraphGermany. An empty baseURL selects GraphGlobal.
+func NewGraphOneDrive(c *http.Client, baseURL string, debug bool) *OneDrive {
+	if baseURL == "" {
+		baseURL = GraphGlobal
+	}
+	drive := NewOneDrive(c, debug)
+	drive.BaseURL = baseURL
+	drive.Graph = true
+	return drive
+}
+
+// resolvePath maps a request URI written against the legacy API's layout onto
+// the configured endpoint. In Graph mode the default drive and the drive
+// collection belong to DriveOwner, so "/drive/..." becomes "/me/drive/..."
+// and "/drives" becomes "/me/drives". Paths to a specific drive are the same
+// in both APIs.
+func (od *OneDrive) resolvePath(uri string) string {
+	if !od.Graph {
+		return uri
+	}
+	owner := od.DriveOwner
+	if owner == "" {
+		owner = "/me"
+	}
+	switch {
+	case uri == "/drive", strings.HasPrefix(uri, "/drive/"), strings.HasPrefix(uri, "/drive?"),
+		uri == "/drives", strings.HasPrefix(uri, "/drives?"):
+		return owner + uri
+	}
+	return uri
+}
+
+// SetDebug toggles debug mode. Unlike assigning to Debug directly, it is safe
+// to call while other goroutines are using the client.
+func (od *OneDrive) SetDebug(debug bool) {
+	od.mu.Lock()
+	defer od.mu.Unlock()
+	od.Debug = debug
+}
+
+func (od *OneDrive) debug() bool {
+	od.mu.RLock()
+	defer od.mu.RUnlock()
+	return od.Debug
+}
+
+// throttleRequest extends the throttle window shared by every goroutine using
+// the client. A window is never shortened by a later, shorter Retry-After.
+func (od *OneDrive) throttleRequest(until time.Time) {
+	od.mu.Lock()
+	defer od.mu.Unlock()
+	if until.After(od.throttle) {
+		od.throttle = until
+	}
+}
+
+// throttledUntil returns the end of the current throttle window.
+func (od *OneDrive) throttledUntil() time.Time {
+	od.mu.RLock()
+	defer od.mu.RUnlock()
+	return od.throttle
+}
+
+// preauthClient returns the client used for pre-authenticated URLs.
+func (od *OneDrive) preauthClient() *http.Client {
+	if od.PreauthClient != nil {
+		return od.PreauthClient
+	}
+	return http.DefaultClient
 }
@@ -1,9 +1,13 @@
 package onedrive
 
 import (
+	"bytes"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
 )
 
 var (
@@ -33,3 +37,150 @@ func fileWrapperHandler(file string, status int) http.HandlerFunc {
 		w.Write(b)
 	}
 }
+
+// fileTemplateHandler is like fileWrapperHandler but replaces {{baseURL}} in
+// the fixture with the URL of the test server, for fixtures containing
+// absolute links such as @odata.nextLink.
+func fileTemplateHandler(file string, status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+		w.Write(bytes.Replace(b, []byte("{{baseURL}}"), []byte(server.URL), -1))
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive", fileWrapperHandler("fixtures/drive.valid.default.json", http.StatusOK))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			oneDrive.SetDebug(i%2 == 0)
+			if _, _, err := oneDrive.Drives.GetDefault(); err != nil {
+				t.Errorf("[%d] %s", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestSharedThrottleWindow(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.RetryPolicy = fastRetryPolicy()
+
+	var (
+		mu        sync.Mutex
+		throttled time.Time
+		early     int
+	)
+	mux.HandleFunc("/drive", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if throttled.IsZero() {
+			throttled = time.Now().Add(time.Second)
+			w.Header().Set("Retry-After", "1")
+			fileWrapperHandler("fixtures/request.invalid.tooManyRequests.json", statusTooManyRequests)(w, r)
+			return
+		}
+		if time.Now().Before(throttled) {
+			early++
+		}
+		fileWrapperHandler("fixtures/drive.valid.default.json", http.StatusOK)(w, r)
+	})
+
+	// The first request is not retried, so it only records the window.
+	oneDrive.RetryPolicy.MaxAttempts = 1
+	if _, _, err := oneDrive.Drives.GetDefault(); err == nil {
+		t.Fatal("Expected tooManyRequests error but none occured")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, _, err := oneDrive.Drives.GetDefault(); err != nil {
+				t.Errorf("[%d] %s", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if early != 0 {
+		t.Errorf("Got %d requests inside the throttle window Expected 0", early)
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	tt := []struct {
+		graph      bool
+		driveOwner string
+		in, out    string
+	}{
+		{false, "", "/drive/root", "/drive/root"},
+		{false, "/users/123", "/drives", "/drives"},
+		{true, "", "/drive", "/me/drive"},
+		{true, "", "/drive/items/123/children", "/me/drive/items/123/children"},
+		{true, "", "/drives", "/me/drives"},
+		{true, "", "/drives/123", "/drives/123"},
+		{true, "/users/123", "/drive/root", "/users/123/drive/root"},
+		{true, "/sites/456", "/drives", "/sites/456/drives"},
+		{true, "", "/driveless", "/driveless"},
+	}
+	for i, tst := range tt {
+		od := NewOneDrive(http.DefaultClient, false)
+		od.Graph = tst.graph
+		od.DriveOwner = tst.driveOwner
+		if got, want := od.resolvePath(tst.in), tst.out; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestNewGraphOneDrive(t *testing.T) {
+	tt := []struct {
+		baseURL, expected string
+	}{
+		{"", GraphGlobal},
+		{GraphChina, GraphChina},
+	}
+	for i, tst := range tt {
+		od := NewGraphOneDrive(http.DefaultClient, tst.baseURL, false)
+		if got, want := od.BaseURL, tst.expected; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if !od.Graph {
+			t.Errorf("[%d] Expected a Graph client", i)
+		}
+	}
+}
+
+func TestGraphRequests(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive = NewGraphOneDrive(http.DefaultClient, server.URL, false)
+
+	mux.HandleFunc("/me/drives", fileWrapperHandler("fixtures/drive.collection.valid.json", http.StatusOK))
+	mux.HandleFunc("/me/drive/items/graph", fileWrapperHandler("fixtures/item.graph.valid.json", http.StatusOK))
+
+	if _, _, err := oneDrive.Drives.ListAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	item, _, err := oneDrive.Items.Get("graph")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := item.DownloadURL, "https://download-url.com/someid"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
@@ -0,0 +1,166 @@
+package onedrive
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// collectionPage is a single page of a collection as returned by the API.
+type collectionPage[T any] struct {
+	Collection []T    `json:"value"`
+	NextLink   string `json:"@odata.nextLink"`
+	Count      int64  `json:"@odata.count"`
+}
+
+// Pager fetches the pages of a collection one at a time, following
+// @odata.nextLink until the service reports no more pages. Call Next to fetch
+// a page, then Values to read it:
+//
+//	pager := od.Items.ListChildrenPager("root")
+//	for pager.Next() {
+//		for _, item := range pager.Values() {
+//			...
+//		}
+//	}
+//	if err := pager.Err(); err != nil {
+//		...
+//	}
+type Pager[T any] struct {
+	od   *OneDrive
+	next string
+	page *collectionPage[T]
+	resp *http.Response
+	err  error
+}
+
+// newPager returns a Pager starting at uri. If building the URI failed, the
+// Pager reports err instead of fetching anything.
+func newPager[T any](od *OneDrive, uri string, err error) *Pager[T] {
+	return &Pager[T]{od: od, next: uri, err: err}
+}
+
+// Next fetches the next page. It returns false once every page has been
+// fetched or an error occurs, in which case Err returns it.
+func (p *Pager[T]) Next() bool {
+	return p.NextContext(context.Background())
+}
+
+// NextContext is like Next but carries a context.
+func (p *Pager[T]) NextContext(ctx context.Context) bool {
+	if p.err != nil || p.next == "" {
+		return false
+	}
+
+	req, err := p.od.newRequest(ctx, "GET", p.next, nil, nil)
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	page := new(collectionPage[T])
+	p.resp, err = p.od.do(req, page)
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	p.page = page
+	p.next = page.NextLink
+	return true
+}
+
+// Values returns the contents of the current page.
+func (p *Pager[T]) Values() []T {
+	if p.page == nil {
+		return nil
+	}
+	return p.page.Collection
+}
+
+// Count returns the total size of the collection if the service reported it
+// with @odata.count, or zero otherwise.
+func (p *Pager[T]) Count() int64 {
+	if p.page == nil {
+		return 0
+	}
+	return p.page.Count
+}
+
+// NextLink returns the URL of the page which the next call to Next fetches,
+// or an empty string if there are no more pages.
+func (p *Pager[T]) NextLink() string {
+	return p.next
+}
+
+// Response returns the HTTP response of the most recently fetched page.
+func (p *Pager[T]) Response() *http.Response {
+	return p.resp
+}
+
+// Err returns the error which stopped Next, if any.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// All returns an iterator over every value in the collection which lazily
+// fetches pages as it goes. An error ends the iteration after being yielded.
+func (p *Pager[T]) All() iter.Seq2[T, error] {
+	return p.AllContext(context.Background())
+}
+
+// AllContext is like All but carries a context.
+func (p *Pager[T]) AllContext(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for p.NextContext(ctx) {
+			for _, v := range p.Values() {
+				if !yield(v, nil) {
+					return
+				}
+			}
+		}
+		if err := p.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// ListAllPager returns a Pager over all the Drives available to the
+// authenticated user.
+func (ds *DriveService) ListAllPager(query ...*Query) *Pager[*Drive] {
+	uri, err := withQuery("/drives", driveType, query)
+	return newPager[*Drive](ds.OneDrive, uri, err)
+}
+
+// ListChildrenPager returns a Pager over the Items under the Drive root.
+func (ds *DriveService) ListChildrenPager(driveID string, query ...*Query) *Pager[*Item] {
+	uri, err := withQuery(driveChildrenURIFromID(driveID), itemType, query)
+	return newPager[*Item](ds.OneDrive, uri, err)
+}
+
+// AllChildren iterates over every Item under the Drive root.
+func (ds *DriveService) AllChildren(driveID string) iter.Seq2[*Item, error] {
+	return ds.AllChildrenContext(context.Background(), driveID)
+}
+
+// AllChildrenContext is like AllChildren but carries a context and accepts query options.
+func (ds *DriveService) AllChildrenContext(ctx context.Context, driveID string, query ...*Query) iter.Seq2[*Item, error] {
+	return ds.ListChildrenPager(driveID, query...).AllContext(ctx)
+}
+
+// ListChildrenPager returns a Pager over the Items under an Item.
+func (is *ItemService) ListChildrenPager(itemID string, query ...*Query) *Pager[*Item] {
+	uri, err := withQuery(is.itemURI(itemID)+"/children", itemType, query)
+	return newPager[*Item](is.OneDrive, uri, err)
+}
+
+// AllChildren iterates over every Item under an Item.
+func (is *ItemService) AllChildren(itemID string) iter.Seq2[*Item, error] {
+	return is.AllChildrenContext(context.Background(), itemID)
+}
+
+// AllChildrenContext is like AllChildren but carries a context and accepts query options.
+func (is *ItemService) AllChildrenContext(ctx context.Context, itemID string, query ...*Query) iter.Seq2[*Item, error] {
+	return is.ListChildrenPager(itemID, query...).AllContext(ctx)
+}
@@ -0,0 +1,134 @@
+package onedrive
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func multiPageChildrenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("$skiptoken") == "page2" {
+		fileTemplateHandler("fixtures/item.children.page2.json", http.StatusOK)(w, r)
+		return
+	}
+	fileTemplateHandler("fixtures/item.children.page1.json", http.StatusOK)(w, r)
+}
+
+func TestListChildrenNextLink(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/items/some-id/children", multiPageChildrenHandler)
+	items, _, err := oneDrive.Items.ListChildren("some-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := items.NextLink, server.URL+"/drive/items/some-id/children?$skiptoken=page2"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := items.Count, int64(3); got != want {
+		t.Errorf("Got %d Expected %d", got, want)
+	}
+}
+
+func TestPager(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/items/some-id/children", multiPageChildrenHandler)
+
+	var pages [][]string
+	pager := oneDrive.Items.ListChildrenPager("some-id")
+	for pager.Next() {
+		var names []string
+		for _, item := range pager.Values() {
+			names = append(names, item.Name)
+		}
+		pages = append(pages, names)
+	}
+	if err := pager.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"Test folder 1", "Test folder 2"},
+		{"sydney_opera_house_2011-1920x1080.jpg"},
+	}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Errorf("Got %v Expected %v", pages, expected)
+	}
+	if got, want := pager.Count(), int64(3); got != want {
+		t.Errorf("Got %d Expected %d", got, want)
+	}
+	if pager.Next() {
+		t.Error("Expected no more pages")
+	}
+}
+
+func TestPagerAll(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/drive/items/some-id/children", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		multiPageChildrenHandler(w, r)
+	})
+
+	var ids []string
+	for item, err := range oneDrive.Items.AllChildren("some-id") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, item.ID)
+	}
+	if got, want := ids, []string{"0123456789abc!104", "0123456789abc!105", "0123456789abc!110"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+
+	// Stopping early must not fetch pages which are never used.
+	requests = 0
+	for range oneDrive.Items.AllChildren("some-id") {
+		break
+	}
+	if got, want := requests, 1; got != want {
+		t.Errorf("Got %d Expected %d requests", got, want)
+	}
+}
+
+func TestPagerError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(driveChildrenURIFromID(""), fileWrapperHandler("fixtures/request.invalid.notFound.json", http.StatusNotFound))
+
+	var errs []error
+	for _, err := range oneDrive.Drives.AllChildren("") {
+		errs = append(errs, err)
+	}
+	if got, want := len(errs), 1; got != want {
+		t.Fatalf("Got %d Expected %d errors", got, want)
+	}
+	if !errors.Is(errs[0], ErrNotFound) {
+		t.Errorf("Got %v Expected %v", errs[0], ErrNotFound)
+	}
+}
+
+func TestListAllPager(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drives", fileWrapperHandler("fixtures/drive.collection.valid.json", http.StatusOK))
+
+	var drives []*Drive
+	for drive, err := range oneDrive.Drives.ListAllPager().All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		drives = append(drives, drive)
+	}
+	if got, want := drives, []*Drive{expectedDefaultDrive}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+}
@@ -0,0 +1,214 @@
+package onedrive
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ParallelDownload configures DownloadParallel. The zero value uses the
+// defaults described on each field.
+type ParallelDownload struct {
+	// Segments is the number of segments downloaded concurrently, 4 when
+	// zero.
+	Segments int
+	// MinSegmentSize is the smallest segment a file is split into, 4MiB when
+	// zero, so that small files are not split needlessly.
+	MinSegmentSize int64
+	// SegmentAttempts is the number of times a segment is attempted before
+	// the download fails, 3 when zero. Each segment is retried on its own.
+	SegmentAttempts int
+	// SkipVerify disables checking the downloaded content against the hashes
+	// reported by the service.
+	SkipVerify bool
+}
+
+func (pd *ParallelDownload) segments() int {
+	if pd == nil || pd.Segments <= 0 {
+		return 4
+	}
+	return pd.Segments
+}
+
+func (pd *ParallelDownload) minSegmentSize() int64 {
+	if pd == nil || pd.MinSegmentSize <= 0 {
+		return 4 << 20
+	}
+	return pd.MinSegmentSize
+}
+
+func (pd *ParallelDownload) segmentAttempts() int {
+	if pd == nil || pd.SegmentAttempts <= 0 {
+		return 3
+	}
+	return pd.SegmentAttempts
+}
+
+// segment is a byte range of a file downloaded by DownloadParallel.
+type segment struct {
+	offset, length int64
+}
+
+// splitSegments splits size bytes into at most n segments of at least min
+// bytes each.
+func splitSegments(size int64, n int, min int64) []segment {
+	length := (size + int64(n) - 1) / int64(n)
+	if length < min {
+		length = min
+	}
+
+	var segments []segment
+	for offset := int64(0); offset < size; offset += length {
+		if offset+length > size {
+			length = size - offset
+		}
+		segments = append(segments, segment{offset, length})
+	}
+	return segments
+}
+
+// DownloadParallel downloads the file with the given itemID into w, fetching
+// segments of the file concurrently with ranged requests. A segment which
+// fails is retried on its own without affecting the others.
+//
+// Unless opts.SkipVerify is set, the content is checked against the hashes of
+// the item and an *IntegrityError is returned if they differ. The
+// QuickXorHash is computed as segments arrive. The other hashes have to be
+// computed over the file in order, so they are only checked when w is also an
+// io.ReaderAt, such as an *os.File, which is read back once every segment has
+// been written. The returned response is the one which carried the item's
+// metadata.
+func (is *ItemService) DownloadParallel(itemID string, w io.WriterAt, opts *ParallelDownload) (*Item, *http.Response, error) {
+	return is.DownloadParallelContext(context.Background(), itemID, w, opts)
+}
+
+// DownloadParallelContext is like DownloadParallel but carries a context.
+func (is *ItemService) DownloadParallelContext(ctx context.Context, itemID string, w io.WriterAt, opts *ParallelDownload) (*Item, *http.Response, error) {
+	item, resp, err := is.getItem(ctx, is.itemURI(itemID), nil)
+	if err != nil {
+		return nil, resp, err
+	}
+	if item.File == nil {
+		return nil, resp, fmt.Errorf("onedrive: item %s is not a file", itemID)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	segments := make(chan segment)
+	go func() {
+		defer close(segments)
+		for _, seg := range splitSegments(item.Size, opts.segments(), opts.minSegmentSize()) {
+			select {
+			case segments <- seg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		sum      = newQuickXorHashAt(0)
+	)
+	for i := 0; i < opts.segments(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seg := range segments {
+				h, err := is.downloadSegment(ctx, itemID, w, seg, opts.segmentAttempts())
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				if err == nil {
+					sum.combine(h)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, resp, firstErr
+	}
+	if opts != nil && opts.SkipVerify {
+		return item, resp, nil
+	}
+	if err := verifyDownload(item, w, sum); err != nil {
+		return nil, resp, err
+	}
+
+	return item, resp, nil
+}
+
+// downloadSegment writes a segment of the file into w, attempting it up to
+// attempts times, and returns the QuickXorHash of the segment.
+func (is *ItemService) downloadSegment(ctx context.Context, itemID string, w io.WriterAt, seg segment, attempts int) (*quickXorHash, error) {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 && is.RetryPolicy != nil {
+			if err := sleep(ctx, is.RetryPolicy.backoff(attempt-1)); err != nil {
+				return nil, err
+			}
+		}
+
+		var body io.ReadCloser
+		body, _, err = is.DownloadRangeContext(ctx, itemID, seg.offset, seg.length)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		h := newQuickXorHashAt(seg.offset)
+		dst := io.MultiWriter(io.NewOffsetWriter(w, seg.offset), h)
+		var n int64
+		n, err = io.Copy(dst, body)
+		body.Close()
+		if err == nil && n != seg.length {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil {
+			return h, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("onedrive: downloading bytes %d-%d: %w", seg.offset, seg.offset+seg.length-1, err)
+}
+
+// verifyDownload checks the downloaded content against the hashes of item,
+// preferring the QuickXorHash which was computed while downloading.
+func verifyDownload(item *Item, w io.WriterAt, quickXor *quickXorHash) error {
+	expected := itemHashes(item)
+	if expected == nil {
+		return nil
+	}
+	if expected.QuickXorHash != "" {
+		return verifyHashes(expected, &HashesFacet{QuickXorHash: base64.StdEncoding.EncodeToString(quickXor.Sum(nil))})
+	}
+	if expected.Sha256Hash == "" && expected.Sha1Hash == "" && expected.Crc32Hash == "" {
+		return nil
+	}
+
+	r, ok := w.(io.ReaderAt)
+	if !ok {
+		return fmt.Errorf("onedrive: cannot verify the hashes of item %s without reading back the download", item.ID)
+	}
+	actual, err := hashReaderAt(r, item.Size)
+	if err != nil {
+		return err
+	}
+	return verifyHashes(expected, actual)
+}
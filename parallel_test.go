@@ -0,0 +1,152 @@
+package onedrive
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writerAt is an in-memory io.WriterAt which, unlike *os.File, cannot be read
+// back.
+type writerAt struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if end := int(off) + len(p); end > len(w.buf) {
+		w.buf = append(w.buf, make([]byte, end-len(w.buf))...)
+	}
+	return copy(w.buf[off:], p), nil
+}
+
+// parallelHandlers serves item 130 described by fixture, redirecting its
+// content to a download URL which serves downloadContent. The first request
+// for the range in flaky is cut off half way through.
+func parallelHandlers(fixture, flaky string) map[string]int {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+
+	mux.HandleFunc("/drive/items/130", fileWrapperHandler(fixture, http.StatusOK))
+	mux.HandleFunc("/drive/items/130/content", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, server.URL+"/download/130", http.StatusFound)
+	})
+	mux.HandleFunc("/download/130", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.Header.Get("Range")]++
+		n := requests[r.Header.Get("Range")]
+		mu.Unlock()
+
+		if r.Header.Get("Range") == flaky && n == 1 {
+			w.Header().Set("Content-Length", "9")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte("jkl"))
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader([]byte(downloadContent)))
+	})
+	return requests
+}
+
+func TestSplitSegments(t *testing.T) {
+	tt := []struct {
+		size        int64
+		n           int
+		min         int64
+		expectedOut []segment
+	}{
+		{26, 3, 1, []segment{{0, 9}, {9, 9}, {18, 8}}},
+		{26, 3, 20, []segment{{0, 20}, {20, 6}}},
+		{26, 1, 1, []segment{{0, 26}}},
+		{0, 4, 1, nil},
+	}
+	for i, tst := range tt {
+		if got, want := splitSegments(tst.size, tst.n, tst.min), tst.expectedOut; !reflect.DeepEqual(got, want) {
+			t.Errorf("[%d] Got %v Expected %v", i, got, want)
+		}
+	}
+}
+
+func TestDownloadParallel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := parallelHandlers("fixtures/item.download.valid.json", "bytes=9-17")
+	oneDrive.RetryPolicy = fastRetryPolicy()
+
+	w := new(writerAt)
+	item, resp, err := oneDrive.Items.DownloadParallel("130", w, &ParallelDownload{Segments: 3, MinSegmentSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := item.Size, int64(26); got != want {
+		t.Errorf("Got %d Expected %d", got, want)
+	}
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Errorf("Got %d Expected %d", got, want)
+	}
+	if got, want := string(w.buf), downloadContent; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+
+	// Only the segment which failed is fetched again.
+	expected := map[string]int{"bytes=0-8": 1, "bytes=9-17": 2, "bytes=18-25": 1}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("Got %v Expected %v", requests, expected)
+	}
+}
+
+func TestDownloadParallelVerify(t *testing.T) {
+	tt := []struct {
+		fixture     string
+		readable    bool
+		skipVerify  bool
+		expectedErr error
+	}{
+		{"fixtures/item.download.valid.json", false, false, nil},
+		{"fixtures/item.download.sha1.json", true, false, nil},
+		{"fixtures/item.download.mismatch.json", false, false, ErrHashMismatch},
+		{"fixtures/item.download.mismatch.json", false, true, nil},
+	}
+	for i, tst := range tt {
+		setup()
+		parallelHandlers(tst.fixture, "")
+
+		var w io.WriterAt = new(writerAt)
+		if tst.readable {
+			file, err := os.Create(filepath.Join(t.TempDir(), "alphabet.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			w = file
+		}
+
+		_, _, err := oneDrive.Items.DownloadParallel("130", w, &ParallelDownload{MinSegmentSize: 10, SkipVerify: tst.skipVerify})
+		if !errors.Is(err, tst.expectedErr) {
+			t.Errorf("[%d] Got %v Expected %v", i, err, tst.expectedErr)
+		}
+		teardown()
+	}
+}
+
+func TestDownloadParallelSegmentFails(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/items/130", fileWrapperHandler("fixtures/item.download.valid.json", http.StatusOK))
+	mux.HandleFunc("/drive/items/130/content", fileWrapperHandler("fixtures/request.invalid.notFound.json", http.StatusNotFound))
+
+	if _, _, err := oneDrive.Items.DownloadParallel("130", new(writerAt), nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Got %v Expected %v", err, ErrNotFound)
+	}
+}
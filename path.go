@@ -0,0 +1,100 @@
+package onedrive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// drivePathURI returns a valid request URI for the item at itemPath, relative
+// to the root of the drive with the given ID, using the root:/path: syntax.
+// Each segment of the path is escaped separately so that names containing
+// characters such as '#', '?' or '%' address the right item.
+// See: http://onedrive.github.io/misc/addressing.htm
+func drivePathURI(driveID, itemPath string) string {
+	itemPath = strings.Trim(itemPath, "/")
+	if itemPath == "" {
+		return driveURIFromID(driveID) + "/root"
+	}
+
+	segments := strings.Split(itemPath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf("%s/root:/%s:", driveURIFromID(driveID), strings.Join(segments, "/"))
+}
+
+// pathURI returns the request URI of the item at itemPath in the drive
+// addressed by the ItemService.
+func (is *ItemService) pathURI(itemPath string) string {
+	return drivePathURI(is.driveID, itemPath)
+}
+
+// GetByPath returns the item at itemPath, relative to the root of the drive,
+// e.g. "/Documents/Reports/q3.xlsx".
+func (is *ItemService) GetByPath(itemPath string) (*Item, *http.Response, error) {
+	return is.GetByPathContext(context.Background(), itemPath)
+}
+
+// GetByPathContext is like GetByPath but carries a context and accepts query options.
+func (is *ItemService) GetByPathContext(ctx context.Context, itemPath string, query ...*Query) (*Item, *http.Response, error) {
+	return is.getItem(ctx, is.pathURI(itemPath), query)
+}
+
+// ListChildrenByPath returns a collection of all the Items under the folder at
+// itemPath.
+func (is *ItemService) ListChildrenByPath(itemPath string) (*Items, *http.Response, error) {
+	return is.ListChildrenByPathContext(context.Background(), itemPath)
+}
+
+// ListChildrenByPathContext is like ListChildrenByPath but carries a context and accepts query options.
+func (is *ItemService) ListChildrenByPathContext(ctx context.Context, itemPath string, query ...*Query) (*Items, *http.Response, error) {
+	return is.listChildren(ctx, is.pathURI(itemPath), query)
+}
+
+// CreateFolderByPath creates a new folder within the folder at parentPath.
+func (is *ItemService) CreateFolderByPath(parentPath, folderName string) (*Item, *http.Response, error) {
+	return is.CreateFolderByPathContext(context.Background(), parentPath, folderName)
+}
+
+// CreateFolderByPathContext is like CreateFolderByPath but carries a context.
+func (is *ItemService) CreateFolderByPathContext(ctx context.Context, parentPath, folderName string) (*Item, *http.Response, error) {
+	return is.createFolder(ctx, is.pathURI(parentPath), folderName)
+}
+
+// UploadByPath uploads the contents of file to itemPath, creating the item or
+// replacing the contents of an existing one. Like SimpleUpload it only
+// supports files up to 100MB in size.
+// See: https://dev.onedrive.com/items/upload_put.htm
+func (is *ItemService) UploadByPath(itemPath string, file *os.File) (*Item, *http.Response, error) {
+	return is.UploadByPathContext(context.Background(), itemPath, file)
+}
+
+// UploadByPathContext is like UploadByPath but carries a context.
+func (is *ItemService) UploadByPathContext(ctx context.Context, itemPath string, file *os.File) (*Item, *http.Response, error) {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if fileInfo.Size() >= oneHundredMB {
+		return nil, nil, ErrFileTooLarge
+	}
+
+	return is.uploadContent(ctx, is.pathURI(itemPath)+"/content", path.Base(itemPath), file)
+}
+
+// DeleteByPath deletes the item at itemPath. As with Delete, the item is moved
+// to the Recycle Bin and an eTag may be given to only delete an unchanged item.
+func (is *ItemService) DeleteByPath(itemPath, eTag string) (bool, *http.Response, error) {
+	return is.DeleteByPathContext(context.Background(), itemPath, eTag)
+}
+
+// DeleteByPathContext is like DeleteByPath but carries a context.
+func (is *ItemService) DeleteByPathContext(ctx context.Context, itemPath, eTag string) (bool, *http.Response, error) {
+	return is.deleteItem(ctx, is.pathURI(itemPath), eTag)
+}
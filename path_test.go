@@ -0,0 +1,85 @@
+package onedrive
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestDrivePathURI(t *testing.T) {
+	tt := []struct {
+		driveID, itemPath, out string
+	}{
+		{"", "", "/drive/root"},
+		{"", "/", "/drive/root"},
+		{"", "/Documents/Reports/q3.xlsx", "/drive/root:/Documents/Reports/q3.xlsx:"},
+		{"", "Documents/", "/drive/root:/Documents:"},
+		{"", "/My Files/50% #1?.txt", "/drive/root:/My%20Files/50%25%20%231%3F.txt:"},
+		{"b!business", "/Shared Documents", "/drives/b!business/root:/Shared%20Documents:"},
+	}
+	for i, tst := range tt {
+		if got, want := drivePathURI(tst.driveID, tst.itemPath), tst.out; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestByPath(t *testing.T) {
+	file, err := ioutil.TempFile("", "upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	file.WriteString("hello world")
+	file.Seek(0, 0)
+
+	tt := []struct {
+		driveID string
+		call    func(is *ItemService) error
+		method  string
+		path    string
+	}{
+		{"", func(is *ItemService) error {
+			_, _, err := is.GetByPath("/Documents/Reports/q3 #1.xlsx")
+			return err
+		}, "GET", "/drive/root:/Documents/Reports/q3%20%231.xlsx:"},
+		{"", func(is *ItemService) error {
+			_, _, err := is.ListChildrenByPath("/Documents")
+			return err
+		}, "GET", "/drive/root:/Documents:/children"},
+		{"b!business", func(is *ItemService) error {
+			_, _, err := is.CreateFolderByPath("/Documents", "Q3 Reports")
+			return err
+		}, "PUT", "/drives/b!business/root:/Documents:/children/Q3%20Reports"},
+		{"", func(is *ItemService) error {
+			_, _, err := is.UploadByPath("/Documents/hello.txt", file)
+			return err
+		}, "PUT", "/drive/root:/Documents/hello.txt:/content"},
+		{"b!business", func(is *ItemService) error {
+			_, _, err := is.DeleteByPath("/Documents/old.txt", "")
+			return err
+		}, "DELETE", "/drives/b!business/root:/Documents/old.txt:"},
+	}
+	for i, tst := range tt {
+		setup()
+		var method, path string
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			method, path = r.Method, r.URL.EscapedPath()
+			fileWrapperHandler("fixtures/item.folder.valid.json", http.StatusOK)(w, r)
+		})
+
+		if err := tst.call(oneDrive.Items.ForDrive(tst.driveID)); err != nil {
+			t.Errorf("[%d] %s", i, err)
+		}
+		teardown()
+
+		if got, want := method, tst.method; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := path, tst.path; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
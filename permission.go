@@ -0,0 +1,223 @@
+package onedrive
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// PermissionService manages the sharing permissions of items. The zero drive
+// addresses items in the user's default drive; use ForDrive to operate on
+// items in any other drive.
+type PermissionService struct {
+	*OneDrive
+	driveID string
+}
+
+// The Permission resource provides information about a sharing permission
+// granted for an Item resource, either to a sharing link or to specific
+// users.
+// See: http://onedrive.github.io/resources/permission.htm
+type Permission struct {
+	ID    string   `json:"id"`
+	Roles []string `json:"roles"`
+	// Link is set for permissions granted through a sharing link.
+	Link      *SharingLink `json:"link"`
+	GrantedTo *IdentitySet `json:"grantedTo"`
+	// Invitation is set for permissions granted by inviting a user.
+	Invitation *SharingInvitation `json:"invitation"`
+	// InheritedFrom references the ancestor the permission is inherited
+	// from, inherited permissions cannot be changed on the item itself.
+	InheritedFrom      *ItemReference `json:"inheritedFrom"`
+	ShareID            string         `json:"shareId"`
+	ExpirationDateTime time.Time      `json:"expirationDateTime"`
+	HasPassword        bool           `json:"hasPassword"`
+}
+
+// Permissions represents a collection of Permissions
+type Permissions struct {
+	Collection []*Permission `json:"value"`
+}
+
+// The SharingInvitation type groups invitation-related data items into a
+// single structure.
+// See: http://onedrive.github.io/facets/invitation_facet.htm
+type SharingInvitation struct {
+	Email          string       `json:"email"`
+	SignInRequired bool         `json:"signInRequired"`
+	InvitedBy      *IdentitySet `json:"invitedBy"`
+}
+
+// Roles which can be granted by a permission.
+const (
+	RoleRead  = "read"
+	RoleWrite = "write"
+	RoleOwner = "owner"
+)
+
+// Recipient identifies someone to invite to an item. Only one of the fields
+// needs to be set.
+// See: http://onedrive.github.io/resources/recipient.htm
+type Recipient struct {
+	Email    string `json:"email,omitempty"`
+	Alias    string `json:"alias,omitempty"`
+	ObjectID string `json:"objectId,omitempty"`
+}
+
+// InviteOptions control how recipients are invited to an item. The zero value,
+// like a nil *InviteOptions, requires recipients to sign in and sends them an
+// invitation.
+type InviteOptions struct {
+	// Message is included in the invitation sent to recipients.
+	Message string
+	// NoSignIn lets recipients access the item without signing in.
+	NoSignIn bool
+	// SkipInvitation grants the permission without emailing the recipients
+	// a sharing link.
+	SkipInvitation bool
+}
+
+// ForDrive returns a PermissionService whose operations address items in the
+// drive with the given ID. An empty driveID addresses the default drive.
+func (ps *PermissionService) ForDrive(driveID string) *PermissionService {
+	return &PermissionService{OneDrive: ps.OneDrive, driveID: driveID}
+}
+
+// permissionsURI returns the request URI of the permissions of an item in the
+// drive addressed by the PermissionService.
+func (ps *PermissionService) permissionsURI(itemID string) string {
+	return driveItemURIFromID(ps.driveID, itemID) + "/permissions"
+}
+
+// permissionURI returns the request URI of a single permission of an item.
+func (ps *PermissionService) permissionURI(itemID, permissionID string) string {
+	return ps.permissionsURI(itemID) + "/" + url.PathEscape(permissionID)
+}
+
+// List returns the permissions of the item, including those inherited from
+// its ancestors.
+// See: http://onedrive.github.io/items/permissions_list.htm
+func (ps *PermissionService) List(itemID string) (*Permissions, *http.Response, error) {
+	return ps.ListContext(context.Background(), itemID)
+}
+
+// ListContext is like List but carries a context.
+func (ps *PermissionService) ListContext(ctx context.Context, itemID string) (*Permissions, *http.Response, error) {
+	req, err := ps.newRequest(ctx, "GET", ps.permissionsURI(itemID), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permissions := new(Permissions)
+	resp, err := ps.do(req, permissions)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return permissions, resp, nil
+}
+
+// Get returns the permission of the item with the given ID.
+// See: http://onedrive.github.io/items/permissions_get.htm
+func (ps *PermissionService) Get(itemID, permissionID string) (*Permission, *http.Response, error) {
+	return ps.GetContext(context.Background(), itemID, permissionID)
+}
+
+// GetContext is like Get but carries a context.
+func (ps *PermissionService) GetContext(ctx context.Context, itemID, permissionID string) (*Permission, *http.Response, error) {
+	req, err := ps.newRequest(ctx, "GET", ps.permissionURI(itemID, permissionID), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permission := new(Permission)
+	resp, err := ps.do(req, permission)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return permission, resp, nil
+}
+
+// UpdateRoles replaces the roles granted by the permission, e.g. to turn
+// write access into RoleRead.
+// See: http://onedrive.github.io/items/permissions_update.htm
+func (ps *PermissionService) UpdateRoles(itemID, permissionID string, roles []string) (*Permission, *http.Response, error) {
+	return ps.UpdateRolesContext(context.Background(), itemID, permissionID, roles)
+}
+
+// UpdateRolesContext is like UpdateRoles but carries a context.
+func (ps *PermissionService) UpdateRolesContext(ctx context.Context, itemID, permissionID string, roles []string) (*Permission, *http.Response, error) {
+	update := struct {
+		Roles []string `json:"roles"`
+	}{roles}
+
+	req, err := ps.newRequest(ctx, "PATCH", ps.permissionURI(itemID, permissionID), nil, update)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permission := new(Permission)
+	resp, err := ps.do(req, permission)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return permission, resp, nil
+}
+
+// Delete removes the permission from the item. Inherited permissions have to
+// be removed from the ancestor they are inherited from.
+// See: http://onedrive.github.io/items/permissions_delete.htm
+func (ps *PermissionService) Delete(itemID, permissionID string) (*http.Response, error) {
+	return ps.DeleteContext(context.Background(), itemID, permissionID)
+}
+
+// DeleteContext is like Delete but carries a context.
+func (ps *PermissionService) DeleteContext(ctx context.Context, itemID, permissionID string) (*http.Response, error) {
+	req, err := ps.newRequest(ctx, "DELETE", ps.permissionURI(itemID, permissionID), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return ps.do(req, nil)
+}
+
+// Invite grants the recipients the given roles on the item, returning the
+// permissions which were created.
+// See: http://onedrive.github.io/items/invite.htm
+func (ps *PermissionService) Invite(itemID string, recipients []Recipient, roles []string, opts *InviteOptions) (*Permissions, *http.Response, error) {
+	return ps.InviteContext(context.Background(), itemID, recipients, roles, opts)
+}
+
+// InviteContext is like Invite but carries a context.
+func (ps *PermissionService) InviteContext(ctx context.Context, itemID string, recipients []Recipient, roles []string, opts *InviteOptions) (*Permissions, *http.Response, error) {
+	if opts == nil {
+		opts = new(InviteOptions)
+	}
+	invite := struct {
+		Recipients     []Recipient `json:"recipients"`
+		Roles          []string    `json:"roles"`
+		Message        string      `json:"message,omitempty"`
+		RequireSignIn  bool        `json:"requireSignIn"`
+		SendInvitation bool        `json:"sendInvitation"`
+	}{recipients, roles, opts.Message, !opts.NoSignIn, !opts.SkipInvitation}
+
+	action := "/action.invite"
+	if ps.Graph {
+		action = "/invite"
+	}
+	req, err := ps.newRequest(ctx, "POST", driveItemURIFromID(ps.driveID, itemID)+action, nil, invite)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permissions := new(Permissions)
+	resp, err := ps.do(req, permissions)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return permissions, resp, nil
+}
@@ -0,0 +1,149 @@
+package onedrive
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestPermissionRequests(t *testing.T) {
+	tt := []struct {
+		graph   bool
+		driveID string
+		call    func(ps *PermissionService) error
+		method  string
+		path    string
+		fixture string
+	}{
+		{false, "", func(ps *PermissionService) error {
+			_, _, err := ps.List("some-id")
+			return err
+		}, "GET", "/drive/items/some-id/permissions", "fixtures/permission.collection.json"},
+		{true, "", func(ps *PermissionService) error {
+			_, _, err := ps.List("root")
+			return err
+		}, "GET", "/me/drive/root/permissions", "fixtures/permission.collection.json"},
+		{true, "b!business", func(ps *PermissionService) error {
+			_, _, err := ps.Get("some-id", "1")
+			return err
+		}, "GET", "/drives/b!business/items/some-id/permissions/1", "fixtures/permission.user.json"},
+		{false, "", func(ps *PermissionService) error {
+			_, _, err := ps.UpdateRoles("some-id", "1", []string{RoleRead})
+			return err
+		}, "PATCH", "/drive/items/some-id/permissions/1", "fixtures/permission.user.json"},
+		{false, "", func(ps *PermissionService) error {
+			_, err := ps.Delete("some-id", "1")
+			return err
+		}, "DELETE", "/drive/items/some-id/permissions/1", ""},
+		{false, "", func(ps *PermissionService) error {
+			_, _, err := ps.Invite("some-id", []Recipient{{Email: "ryan@contoso.com"}}, []string{RoleWrite}, nil)
+			return err
+		}, "POST", "/drive/items/some-id/action.invite", "fixtures/permission.invite.json"},
+		{true, "b!business", func(ps *PermissionService) error {
+			_, _, err := ps.Invite("some-id", []Recipient{{Email: "ryan@contoso.com"}}, []string{RoleWrite}, nil)
+			return err
+		}, "POST", "/drives/b!business/items/some-id/invite", "fixtures/permission.invite.json"},
+	}
+	for i, tst := range tt {
+		setup()
+		oneDrive.Graph = tst.graph
+		var method, path string
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			method, path = r.Method, r.URL.Path
+			if tst.fixture == "" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			fileWrapperHandler(tst.fixture, http.StatusOK)(w, r)
+		})
+
+		if err := tst.call(oneDrive.Permissions.ForDrive(tst.driveID)); err != nil {
+			t.Errorf("[%d] %s", i, err)
+		}
+		teardown()
+
+		if got, want := method, tst.method; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := path, tst.path; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestUpdateRoles(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var body struct {
+		Roles []string `json:"roles"`
+	}
+	mux.HandleFunc("/drive/items/some-id/permissions/1", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		fileWrapperHandler("fixtures/permission.user.json", http.StatusOK)(w, r)
+	})
+
+	permission, _, err := oneDrive.Permissions.UpdateRoles("some-id", "1", []string{RoleRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := body.Roles, []string{RoleRead}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+	if got, want := permission.GrantedTo.User.DisplayName, "Ryan Gregg"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestInvite(t *testing.T) {
+	tt := []struct {
+		opts           *InviteOptions
+		requireSignIn  bool
+		sendInvitation bool
+		message        string
+	}{
+		{nil, true, true, ""},
+		{&InviteOptions{Message: "Here's the file"}, true, true, "Here's the file"},
+		{&InviteOptions{NoSignIn: true, SkipInvitation: true, Message: "Welcome"}, false, false, "Welcome"},
+	}
+	for i, tst := range tt {
+		setup()
+		var body map[string]interface{}
+		mux.HandleFunc("/drive/items/some-id/action.invite", func(w http.ResponseWriter, r *http.Request) {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("[%d] %s", i, err)
+			}
+			fileWrapperHandler("fixtures/permission.invite.json", http.StatusOK)(w, r)
+		})
+
+		permissions, _, err := oneDrive.Permissions.Invite("some-id", []Recipient{{Email: "ryan@contoso.com"}}, []string{RoleWrite}, tst.opts)
+		teardown()
+		if err != nil {
+			t.Errorf("[%d] %s", i, err)
+			continue
+		}
+
+		if got, want := body["requireSignIn"], tst.requireSignIn; got != want {
+			t.Errorf("[%d] Got %v Expected %v", i, got, want)
+		}
+		if got, want := body["sendInvitation"], tst.sendInvitation; got != want {
+			t.Errorf("[%d] Got %v Expected %v", i, got, want)
+		}
+		if got, _ := body["message"].(string); got != tst.message {
+			t.Errorf("[%d] Got %q Expected %q", i, got, tst.message)
+		}
+		recipients := []interface{}{map[string]interface{}{"email": "ryan@contoso.com"}}
+		if got, want := body["recipients"], recipients; !reflect.DeepEqual(got, want) {
+			t.Errorf("[%d] Got %v Expected %v", i, got, want)
+		}
+		if got, want := len(permissions.Collection), 1; got != want {
+			t.Fatalf("[%d] Got %d Expected %d permissions", i, got, want)
+		}
+		if got, want := permissions.Collection[0].Invitation.Email, "ryan@contoso.com"; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
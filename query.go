@@ -0,0 +1,245 @@
+package onedrive
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidQuery is returned when a Query refers to a field the requested
+// resource does not have.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Query builds the OData query options accepted by read methods. The builder
+// methods return the Query so calls can be chained:
+//
+//	q := NewQuery().
+//		Select("id", "name", "children").
+//		Expand("children", NewQuery().Select("id", "name").Top(10)).
+//		OrderBy("name", false)
+//
+// Field names are checked against the JSON names of the requested resource,
+// such as Item or Drive, when the request is made.
+// See: http://onedrive.github.io/odata/optional-query-parameters.htm
+type Query struct {
+	selects   []string
+	expands   []expansion
+	top       int
+	orderBy   []string
+	filter    string
+	skipToken string
+}
+
+type expansion struct {
+	property string
+	query    *Query
+}
+
+// NewQuery returns an empty Query.
+func NewQuery() *Query {
+	return new(Query)
+}
+
+// Select limits the response to the given fields ($select).
+func (q *Query) Select(fields ...string) *Query {
+	q.selects = append(q.selects, fields...)
+	return q
+}
+
+// Expand includes a relationship, such as children or thumbnails, in the
+// response ($expand). nested may be nil, or a Query applied to the expanded
+// resources.
+func (q *Query) Expand(property string, nested *Query) *Query {
+	q.expands = append(q.expands, expansion{property, nested})
+	return q
+}
+
+// Top limits the number of items returned in a page ($top).
+func (q *Query) Top(n int) *Query {
+	q.top = n
+	return q
+}
+
+// OrderBy sorts the response by a field ($orderby). It may be called more
+// than once to sort by several fields.
+func (q *Query) OrderBy(field string, descending bool) *Query {
+	if descending {
+		field += " desc"
+	}
+	q.orderBy = append(q.orderBy, field)
+	return q
+}
+
+// Filter restricts a collection to the items matching an OData filter
+// expression ($filter).
+func (q *Query) Filter(expr string) *Query {
+	q.filter = expr
+	return q
+}
+
+// SkipToken resumes a collection from a token returned by the service
+// ($skipToken). Pagers follow @odata.nextLink instead, which already carries
+// the token.
+func (q *Query) SkipToken(token string) *Query {
+	q.skipToken = token
+	return q
+}
+
+// validate checks every field the Query refers to against the resource type t.
+func (q *Query) validate(t reflect.Type) error {
+	fields := jsonFields(t)
+	check := func(option, field string) error {
+		if _, ok := fields[field]; !ok {
+			return fmt.Errorf("%w: %s has no field %q in %s", ErrInvalidQuery, t.Name(), field, option)
+		}
+		return nil
+	}
+
+	for _, field := range q.selects {
+		if err := check("$select", field); err != nil {
+			return err
+		}
+	}
+	for _, field := range q.orderBy {
+		field = strings.TrimSuffix(field, " desc")
+		if i := strings.Index(field, "/"); i >= 0 {
+			field = field[:i]
+		}
+		if err := check("$orderby", field); err != nil {
+			return err
+		}
+	}
+	for _, exp := range q.expands {
+		if err := check("$expand", exp.property); err != nil {
+			return err
+		}
+		if exp.query != nil {
+			if err := exp.query.validate(fields[exp.property]); err != nil {
+				return err
+			}
+		}
+	}
+	if q.top < 0 {
+		return fmt.Errorf("%w: negative $top %d", ErrInvalidQuery, q.top)
+	}
+	return nil
+}
+
+// options returns the query options in the order they are sent. Nested
+// queries join them with ';' inside the parentheses of an $expand.
+func (q *Query) options() []string {
+	var opts []string
+	if len(q.selects) > 0 {
+		opts = append(opts, "$select="+escapeOption(strings.Join(q.selects, ",")))
+	}
+	if len(q.expands) > 0 {
+		expands := make([]string, len(q.expands))
+		for i, exp := range q.expands {
+			expands[i] = exp.property
+			if exp.query != nil {
+				if nested := exp.query.options(); len(nested) > 0 {
+					expands[i] += "(" + strings.Join(nested, ";") + ")"
+				}
+			}
+		}
+		opts = append(opts, "$expand="+strings.Join(expands, ","))
+	}
+	if q.top > 0 {
+		opts = append(opts, "$top="+strconv.Itoa(q.top))
+	}
+	if len(q.orderBy) > 0 {
+		opts = append(opts, "$orderby="+escapeOption(strings.Join(q.orderBy, ",")))
+	}
+	if q.filter != "" {
+		opts = append(opts, "$filter="+escapeOption(q.filter))
+	}
+	if q.skipToken != "" {
+		opts = append(opts, "$skipToken="+escapeOption(q.skipToken))
+	}
+	return opts
+}
+
+// escapeOption escapes the value of a query option. Spaces are sent as %20
+// rather than '+', and the ',' separating lists is left readable.
+func escapeOption(value string) string {
+	escaped := strings.Replace(url.QueryEscape(value), "+", "%20", -1)
+	return strings.Replace(escaped, "%2C", ",", -1)
+}
+
+// withQuery validates the queries against the resource type t and appends
+// their options to uri. Later queries take precedence over earlier ones for
+// the same option.
+func withQuery(uri string, t reflect.Type, query []*Query) (string, error) {
+	var opts []string
+	seen := make(map[string]int)
+	for _, q := range query {
+		if q == nil {
+			continue
+		}
+		if err := q.validate(t); err != nil {
+			return "", err
+		}
+		for _, opt := range q.options() {
+			name := opt[:strings.Index(opt, "=")]
+			if i, ok := seen[name]; ok {
+				opts[i] = opt
+				continue
+			}
+			seen[name] = len(opts)
+			opts = append(opts, opt)
+		}
+	}
+	if len(opts) == 0 {
+		return uri, nil
+	}
+
+	sep := "?"
+	if strings.Contains(uri, "?") {
+		sep = "&"
+	}
+	return uri + sep + strings.Join(opts, "&"), nil
+}
+
+var (
+	itemType  = reflect.TypeOf(Item{})
+	driveType = reflect.TypeOf(Drive{})
+	shareType = reflect.TypeOf(Share{})
+)
+
+// jsonFields maps the JSON names of a resource's fields to the type of the
+// resource they hold, which is what nested queries are checked against.
+// Collections resolve to the type of their elements and instance
+// annotations, such as @content.downloadUrl, are left out.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || strings.HasPrefix(name, "@") {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		// Collections such as Items are expanded into their elements.
+		if ft.Kind() == reflect.Struct {
+			if value, ok := ft.FieldByName("Collection"); ok {
+				ft = value.Type.Elem()
+			}
+		}
+		fields[name] = ft
+	}
+	return fields
+}
@@ -0,0 +1,136 @@
+package onedrive
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestWithQuery(t *testing.T) {
+	tt := []struct {
+		uri   string
+		query []*Query
+		out   string
+	}{
+		{"/drive/root", nil, "/drive/root"},
+		{"/drive/root", []*Query{nil}, "/drive/root"},
+		{"/drive/root", []*Query{NewQuery().Select("id", "name")}, "/drive/root?$select=id,name"},
+		{"/drive/root/children", []*Query{NewQuery().Top(50).OrderBy("name", false).OrderBy("lastModifiedDateTime", true)},
+			"/drive/root/children?$top=50&$orderby=name,lastModifiedDateTime%20desc"},
+		{"/drive/root", []*Query{NewQuery().Expand("children", NewQuery().Select("id", "name").Top(5)).Expand("thumbnails", nil)},
+			"/drive/root?$expand=children($select=id,name;$top=5),thumbnails"},
+		{"/drive/root/children", []*Query{NewQuery().Filter("file ne null").SkipToken("abc+/=")},
+			"/drive/root/children?$filter=file%20ne%20null&$skipToken=abc%2B%2F%3D"},
+		{"/drive/root/children?$skiptoken=1", []*Query{NewQuery().Top(1)}, "/drive/root/children?$skiptoken=1&$top=1"},
+		{"/drive/root", []*Query{NewQuery().Select("id").Top(1), NewQuery().Select("name")}, "/drive/root?$select=name&$top=1"},
+		{"/drive/root", []*Query{NewQuery().OrderBy("file/mimeType", false)}, "/drive/root?$orderby=file%2FmimeType"},
+	}
+	for i, tst := range tt {
+		got, err := withQuery(tst.uri, itemType, tst.query)
+		if err != nil {
+			t.Errorf("[%d] %s", i, err)
+			continue
+		}
+		if want := tst.out; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestWithQueryInvalid(t *testing.T) {
+	tt := []struct {
+		resource string
+		query    *Query
+	}{
+		{"item", NewQuery().Select("id", "nmae")},
+		{"item", NewQuery().Select("@content.downloadUrl")},
+		{"item", NewQuery().OrderBy("sise", true)},
+		{"item", NewQuery().Expand("childs", nil)},
+		{"item", NewQuery().Expand("children", NewQuery().Select("quota"))},
+		{"item", NewQuery().Expand("thumbnails", NewQuery().Select("name"))},
+		{"item", NewQuery().Top(-1)},
+		{"drive", NewQuery().Select("name")},
+		{"drive", NewQuery().Expand("items", NewQuery().Select("driveType"))},
+	}
+	for i, tst := range tt {
+		resource := itemType
+		if tst.resource == "drive" {
+			resource = driveType
+		}
+		if _, err := withQuery("/", resource, []*Query{tst.query}); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("[%d] Got %v Expected %v", i, err, ErrInvalidQuery)
+		}
+	}
+}
+
+func TestReadMethodsAcceptQuery(t *testing.T) {
+	tt := []struct {
+		call     func() error
+		rawQuery string
+	}{
+		{func() error {
+			_, _, err := oneDrive.Items.GetContext(context.Background(), "root", NewQuery().Expand("children", nil))
+			return err
+		}, "$expand=children"},
+		{func() error {
+			_, _, err := oneDrive.Items.ListChildrenContext(context.Background(), "root", NewQuery().Select("id"))
+			return err
+		}, "$select=id"},
+		{func() error {
+			_, _, err := oneDrive.Items.GetByPathContext(context.Background(), "/Documents", NewQuery().Expand("thumbnails", nil))
+			return err
+		}, "$expand=thumbnails"},
+		{func() error {
+			_, _, err := oneDrive.Drives.ListAllContext(context.Background(), NewQuery().Select("id", "quota"))
+			return err
+		}, "$select=id,quota"},
+		{func() error {
+			_, _, err := oneDrive.Drives.GetDefaultContext(context.Background(), NewQuery().Expand("root", nil))
+			return err
+		}, "$expand=root"},
+		{func() error {
+			pager := oneDrive.Items.ListChildrenPager("root", NewQuery().Top(2))
+			pager.Next()
+			return pager.Err()
+		}, "$top=2"},
+	}
+	for i, tst := range tt {
+		setup()
+		var rawQuery string
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			rawQuery = r.URL.RawQuery
+			w.Write([]byte("{}"))
+		})
+
+		if err := tst.call(); err != nil {
+			t.Errorf("[%d] %s", i, err)
+		}
+		teardown()
+
+		if got, want := rawQuery, tst.rawQuery; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestInvalidQueryIsNotSent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	})
+
+	if _, _, err := oneDrive.Items.GetContext(context.Background(), "root", NewQuery().Select("nmae")); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Got %v Expected %v", err, ErrInvalidQuery)
+	}
+	pager := oneDrive.Drives.ListAllPager(NewQuery().Select("nmae"))
+	if pager.Next() || !errors.Is(pager.Err(), ErrInvalidQuery) {
+		t.Errorf("Got %v Expected %v", pager.Err(), ErrInvalidQuery)
+	}
+	if requests != 0 {
+		t.Errorf("Got %d Expected 0 requests", requests)
+	}
+}
@@ -0,0 +1,107 @@
+package onedrive
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	// QuickXorHashSize is the size of a QuickXorHash checksum in bytes.
+	QuickXorHashSize = 20
+
+	quickXorWidth = QuickXorHashSize * 8
+	quickXorShift = 11
+)
+
+// quickXorHash implements the QuickXorHash used by OneDrive for Business.
+// Byte i of the input is XORed into a 160 bit register at bit 11*i, wrapping
+// around the end of the register, and the length of the input is XORed into
+// the last 64 bits when the sum is taken.
+// See: https://docs.microsoft.com/onedrive/developer/code-snippets/quickxorhash
+type quickXorHash struct {
+	cells  [3]uint64
+	shift  int
+	length int64
+}
+
+// NewQuickXorHash returns a hash.Hash computing the QuickXorHash checksum. The
+// base64 encoding of the sum is what the service reports in
+// HashesFacet.QuickXorHash.
+func NewQuickXorHash() hash.Hash {
+	return new(quickXorHash)
+}
+
+// newQuickXorHashAt returns a QuickXorHash of content which starts at offset
+// within a file. The hashes of the parts of a file can be combined into the
+// hash of the whole file, so parts downloaded concurrently can be hashed as
+// they arrive.
+func newQuickXorHashAt(offset int64) *quickXorHash {
+	return &quickXorHash{shift: int(offset % quickXorWidth * quickXorShift % quickXorWidth)}
+}
+
+func (h *quickXorHash) Write(p []byte) (int, error) {
+	// Bytes 160 apart are XORed in at the same bit, so they are folded
+	// together before being XORed into the register.
+	shift := h.shift
+	for i := 0; i < len(p) && i < quickXorWidth; i++ {
+		var b byte
+		for j := i; j < len(p); j += quickXorWidth {
+			b ^= p[j]
+		}
+		h.xorAt(shift, b)
+		shift = (shift + quickXorShift) % quickXorWidth
+	}
+
+	h.shift = (h.shift + quickXorShift*(len(p)%quickXorWidth)) % quickXorWidth
+	h.length += int64(len(p))
+	return len(p), nil
+}
+
+// xorAt XORs b into the register at bit shift. The last cell only holds 32
+// bits, so bits which spill over it wrap around to the first cell.
+func (h *quickXorHash) xorAt(shift int, b byte) {
+	cell, offset := shift/64, shift%64
+	width := 64
+	if cell == len(h.cells)-1 {
+		width = quickXorWidth % 64
+	}
+
+	h.cells[cell] ^= uint64(b) << offset
+	if offset > width-8 {
+		h.cells[(cell+1)%len(h.cells)] ^= uint64(b) >> (width - offset)
+	}
+}
+
+// combine XORs the hash of another part of the same file into h.
+func (h *quickXorHash) combine(other *quickXorHash) {
+	for i := range h.cells {
+		h.cells[i] ^= other.cells[i]
+	}
+	h.length += other.length
+}
+
+func (h *quickXorHash) Sum(b []byte) []byte {
+	var sum [QuickXorHashSize]byte
+	binary.LittleEndian.PutUint64(sum[0:], h.cells[0])
+	binary.LittleEndian.PutUint64(sum[8:], h.cells[1])
+	binary.LittleEndian.PutUint32(sum[16:], uint32(h.cells[2]))
+
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(h.length))
+	for i, l := range length {
+		sum[QuickXorHashSize-len(length)+i] ^= l
+	}
+	return append(b, sum[:]...)
+}
+
+func (h *quickXorHash) Reset() {
+	*h = quickXorHash{}
+}
+
+func (h *quickXorHash) Size() int {
+	return QuickXorHashSize
+}
+
+func (h *quickXorHash) BlockSize() int {
+	return 64
+}
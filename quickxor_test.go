@@ -0,0 +1,63 @@
+package onedrive
+
+import (
+	"encoding/base64"
+	"math/rand"
+	"testing"
+)
+
+// referenceQuickXor computes the QuickXorHash one bit at a time, straight from
+// its definition.
+func referenceQuickXor(p []byte) []byte {
+	var sum [QuickXorHashSize]byte
+	for i, b := range p {
+		start := i * quickXorShift
+		for bit := 0; bit < 8; bit++ {
+			if b&(1<<bit) == 0 {
+				continue
+			}
+			pos := (start + bit) % quickXorWidth
+			sum[pos/8] ^= 1 << (pos % 8)
+		}
+	}
+	length := uint64(len(p))
+	for i := 0; i < 8; i++ {
+		sum[QuickXorHashSize-8+i] ^= byte(length >> (8 * i))
+	}
+	return sum[:]
+}
+
+func TestQuickXorHash(t *testing.T) {
+	if got, want := base64.StdEncoding.EncodeToString(NewQuickXorHash().Sum(nil)), "AAAAAAAAAAAAAAAAAAAAAAAAAAA="; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for _, size := range []int{1, 7, 8, 159, 160, 161, 320, 1000, 4096} {
+		p := make([]byte, size)
+		rnd.Read(p)
+		want := base64.StdEncoding.EncodeToString(referenceQuickXor(p))
+
+		// Write the input in uneven pieces to cover the carried shift.
+		h := NewQuickXorHash()
+		for rest := p; len(rest) > 0; {
+			n := 1 + rnd.Intn(len(rest))
+			h.Write(rest[:n])
+			rest = rest[n:]
+		}
+		if got := base64.StdEncoding.EncodeToString(h.Sum(nil)); got != want {
+			t.Errorf("[%d] Got %q Expected %q", size, got, want)
+		}
+
+		// Hashes of the parts of the input combine into the whole.
+		split := rnd.Intn(size)
+		whole := newQuickXorHashAt(0)
+		part := newQuickXorHashAt(int64(split))
+		whole.Write(p[:split])
+		part.Write(p[split:])
+		whole.combine(part)
+		if got := base64.StdEncoding.EncodeToString(whole.Sum(nil)); got != want {
+			t.Errorf("[%d] combined Got %q Expected %q", size, got, want)
+		}
+	}
+}
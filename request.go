@@ -2,11 +2,12 @@ package onedrive
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -16,19 +17,55 @@ const (
 	statusNoContent           int = 204
 )
 
-func createRequestBody(body interface{}) (io.ReadWriter, error) {
-	var buf io.ReadWriter
-	if body != nil {
-		buf = new(bytes.Buffer)
-		err := json.NewEncoder(buf).Encode(body)
-		if err != nil {
+// createRequestBody returns the body to send for a request. Readers are sent
+// as they are, anything else is encoded as JSON.
+func createRequestBody(body interface{}) (io.Reader, error) {
+	switch body := body.(type) {
+	case nil:
+		return nil, nil
+	case io.Reader:
+		return body, nil
+	}
+
+	buf := new(bytes.Buffer)
+	if err := json.NewEncoder(buf).Encode(body); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// seekableBody allows a request whose body is an io.ReadSeeker, such as an
+// *os.File, to be replayed by rewinding it to the position it started from.
+func seekableBody(req *http.Request, body io.ReadSeeker) error {
+	start, err := body.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	end, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := body.Seek(start, io.SeekStart); err != nil {
+		return err
+	}
+
+	req.ContentLength = end - start
+	req.Body = io.NopCloser(body)
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := body.Seek(start, io.SeekStart); err != nil {
 			return nil, err
 		}
+		return io.NopCloser(body), nil
 	}
-	return buf, nil
+	return nil
 }
 
+// calculateThrottle returns the time until which requests should be held
+// back. Retry-After is either a number of seconds or an HTTP date.
 func calculateThrottle(currentTime time.Time, retryAfter string) (time.Time, error) {
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return date, nil
+	}
 	duration, err := time.ParseDuration(retryAfter + "s")
 	if err != nil {
 		return time.Time{}, err
@@ -36,23 +73,32 @@ func calculateThrottle(currentTime time.Time, retryAfter string) (time.Time, err
 	return currentTime.Add(duration), nil
 }
 
-func (od *OneDrive) newRequest(method, uri string, requestHeaders map[string]string, body interface{}) (*http.Request, error) {
-	if !time.Now().After(od.throttle) {
-		return nil, errors.New(fmt.Sprintf("you are making too many requests. Please wait: %s", od.throttle.Sub(time.Now())))
-	}
-
+func (od *OneDrive) newRequest(ctx context.Context, method, uri string, requestHeaders map[string]string, body interface{}) (*http.Request, error) {
 	requestBody, err := createRequestBody(body)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(method, od.BaseURL+uri, requestBody)
+	// Absolute URLs, such as @odata.nextLink, are used as they are.
+	reqURL := uri
+	if !strings.HasPrefix(uri, "https://") && !strings.HasPrefix(uri, "http://") {
+		reqURL = od.BaseURL + od.resolvePath(uri)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, requestBody)
 	if err != nil {
 		return nil, err
 	}
 
+	if seeker, ok := requestBody.(io.ReadSeeker); ok && req.GetBody == nil {
+		if err := seekableBody(req, seeker); err != nil {
+			return nil, err
+		}
+	}
+
 	acceptHeader := "application/json"
-	if od.Debug {
+	// Graph does not support the pretty printing format parameter.
+	if od.debug() && !od.Graph {
 		acceptHeader += ";format=pretty"
 	}
 
@@ -69,33 +115,103 @@ func (od *OneDrive) newRequest(method, uri string, requestHeaders map[string]str
 	return req, nil
 }
 
+// waitForThrottle blocks until the throttle window imposed by the service has
+// passed. If the window is longer than the retry policy is willing to wait,
+// or retries are disabled, an error is returned straight away. The window may
+// be extended by another goroutine while waiting, so it is checked again
+// after every sleep.
+func (od *OneDrive) waitForThrottle(ctx context.Context) error {
+	for {
+		wait := time.Until(od.throttledUntil())
+		if wait <= 0 {
+			return nil
+		}
+		if od.RetryPolicy == nil || wait > od.RetryPolicy.MaxRetryAfter {
+			return fmt.Errorf("you are making too many requests. Please wait: %s", wait)
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// do sends the request, retrying it according to the client's RetryPolicy,
+// and decodes a successful response into decodeInto.
 func (od *OneDrive) do(req *http.Request, decodeInto interface{}) (*http.Response, error) {
-	resp, err := od.Client.Do(req)
+	return od.doWith(od.Client, req, decodeInto)
+}
+
+// doWith is like do but sends the request with the given client.
+func (od *OneDrive) doWith(client *http.Client, req *http.Request, decodeInto interface{}) (*http.Response, error) {
+	resp, err := od.doStream(client, req)
 	if err != nil {
-		return nil, err
+		return resp, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode <= statusInsufficientStorage {
-		if resp.StatusCode == statusTooManyRequests {
-			retryAfter, err := calculateThrottle(time.Now(), resp.Header.Get("Retry-After"))
-			if err != nil {
-				return resp, err
-			}
-			od.throttleRequest(retryAfter)
-		}
-		newErr := new(Error)
-		if err := json.NewDecoder(resp.Body).Decode(newErr); err != nil {
+	if decodeInto != nil {
+		if err := json.NewDecoder(resp.Body).Decode(decodeInto); err != nil {
 			return resp, err
 		}
-		return resp, newErr
 	}
 
-	if decodeInto != nil {
-		if err := json.NewDecoder(resp.Body).Decode(decodeInto); err != nil {
+	return resp, nil
+}
+
+// doStream sends the request with client, retrying it according to the
+// client's RetryPolicy, and returns a successful response with its body left
+// for the caller to read and close.
+func (od *OneDrive) doStream(client *http.Client, req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		if err := od.waitForThrottle(req.Context()); err != nil {
+			return nil, err
+		}
+
+		resp, err := od.send(client, req)
+		wait, retry := od.RetryPolicy.retryDelay(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		if err := sleep(req.Context(), wait); err != nil {
 			return resp, err
 		}
+		if err := rewind(req); err != nil {
+			return resp, err
+		}
+	}
+}
+
+// send makes a single attempt at the request. Redirects are only returned to
+// the caller when client does not follow them, as when resolving download
+// URLs; any other response outside the 2xx range is turned into an *Error.
+func (od *OneDrive) send(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if isRedirect(resp) {
+		return resp, nil
 	}
 
-	return resp, err
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		if resp.StatusCode == statusTooManyRequests {
+			// A missing or malformed Retry-After still leaves the API error
+			// to be reported, the retry policy falls back to its backoff.
+			if retryAfter, err := calculateThrottle(time.Now(), resp.Header.Get("Retry-After")); err == nil {
+				od.throttleRequest(retryAfter)
+			}
+		}
+		return resp, newErrorFromResponse(resp)
+	}
+
+	return resp, nil
+}
+
+// isRedirect reports whether resp redirects to another location.
+func isRedirect(resp *http.Response) bool {
+	return resp.StatusCode >= http.StatusMultipleChoices &&
+		resp.StatusCode < http.StatusBadRequest &&
+		resp.Header.Get("Location") != ""
 }
@@ -1,6 +1,8 @@
 package onedrive
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"testing"
@@ -53,7 +55,7 @@ func TestNewRequest(t *testing.T) {
 	for i, tst := range tt {
 		oneDrive.Debug = tst.debug
 
-		req, err := oneDrive.newRequest(tst.method, tst.uri, tst.requestHeaders, tst.body)
+		req, err := oneDrive.newRequest(context.Background(), tst.method, tst.uri, tst.requestHeaders, tst.body)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -112,3 +114,28 @@ func TestThrottledRequest(t *testing.T) {
 	}
 
 }
+
+func TestRequestContextCancelled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	unblock := make(chan struct{})
+	defer close(unblock)
+	mux.HandleFunc("/drive", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-unblock:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	drive, _, err := oneDrive.Drives.GetDefaultContext(ctx)
+	if drive != nil {
+		t.Fatalf("Expected no drive to be returned, got %v", *drive)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Got %v Expected %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,284 @@
+package onedrive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ggordan/go-onedrive/internal/atomicfile"
+)
+
+// UploadSource identifies the local file an upload was started with, so that
+// a resumed upload can tell whether the file has changed since.
+type UploadSource struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// uploadSource returns the UploadSource of file.
+func uploadSource(file *os.File) (UploadSource, error) {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return UploadSource{}, err
+	}
+	path, err := filepath.Abs(file.Name())
+	if err != nil {
+		return UploadSource{}, err
+	}
+	return UploadSource{Path: path, Size: fileInfo.Size(), ModTime: fileInfo.ModTime().UTC()}, nil
+}
+
+// equal reports whether both sources describe the same, unchanged file.
+func (us UploadSource) equal(other UploadSource) bool {
+	return us.Path == other.Path && us.Size == other.Size && us.ModTime.Equal(other.ModTime)
+}
+
+// UploadState is the serializable state of an upload made by ResumeUpload. It
+// holds everything needed to continue the upload from another process.
+type UploadState struct {
+	UploadURL          string       `json:"uploadUrl"`
+	ExpirationDateTime time.Time    `json:"expirationDateTime"`
+	Source             UploadSource `json:"source"`
+	// ConfirmedRanges lists the ranges of the file the service has
+	// acknowledged, such as "0-655359".
+	ConfirmedRanges []string `json:"confirmedRanges"`
+}
+
+// newUploadState returns the state of an upload of source to session.
+func newUploadState(session *UploadSession, source UploadSource) (*UploadState, error) {
+	confirmed, err := confirmedRanges(session.NextExpectedRanges, source.Size)
+	if err != nil {
+		return nil, err
+	}
+	return &UploadState{
+		UploadURL:          session.UploadURL,
+		ExpirationDateTime: session.ExpirationDateTime,
+		Source:             source,
+		ConfirmedRanges:    confirmed,
+	}, nil
+}
+
+// confirmedRanges returns the ranges of a size byte file which are not among
+// the expected ranges of an upload session.
+func confirmedRanges(expected []string, size int64) ([]string, error) {
+	type byteRange struct{ start, end int64 }
+	missing := make([]byteRange, 0, len(expected))
+	for _, r := range expected {
+		start, end, err := (&UploadSession{NextExpectedRanges: []string{r}}).nextRange()
+		if err != nil {
+			return nil, err
+		}
+		if end < 0 {
+			end = size - 1
+		}
+		missing = append(missing, byteRange{start, end})
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].start < missing[j].start })
+
+	confirmed := []string{}
+	next := int64(0)
+	for _, r := range missing {
+		if r.start > next {
+			confirmed = append(confirmed, fmt.Sprintf("%d-%d", next, r.start-1))
+		}
+		if r.end+1 > next {
+			next = r.end + 1
+		}
+	}
+	if next < size {
+		confirmed = append(confirmed, fmt.Sprintf("%d-%d", next, size-1))
+	}
+	return confirmed, nil
+}
+
+// ResumeUpload is like ResumableUpload but saves the state of the upload to
+// store after every fragment, so that an upload interrupted by a crash or
+// restart continues where it left off when ResumeUpload is called again with
+// the same folder, file and store. The saved session is only resumed if the
+// local file is unchanged; otherwise ErrUploadSourceChanged is returned and
+// the state must be deleted from the store to start over. An expired session
+// is replaced with a new one. The state is deleted once the upload completes.
+func (is *ItemService) ResumeUpload(folderID string, file *os.File, store UploadStateStore, opts *UploadOptions) (*Item, *http.Response, error) {
+	return is.ResumeUploadContext(context.Background(), folderID, file, store, opts)
+}
+
+// ResumeUploadContext is like ResumeUpload but carries a context.
+func (is *ItemService) ResumeUploadContext(ctx context.Context, folderID string, file *os.File, store UploadStateStore, opts *UploadOptions) (*Item, *http.Response, error) {
+	source, err := uploadSource(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if source.Size == 0 {
+		return is.ResumableUploadContext(ctx, folderID, file, opts)
+	}
+
+	name := filepath.Base(file.Name())
+	key := is.driveID + "/" + folderID + "/" + name
+	state, err := store.LoadUploadState(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, resp, err := is.resumeSession(ctx, state, source)
+	if err != nil {
+		return nil, resp, err
+	}
+	if session == nil {
+		if session, resp, err = is.CreateUploadSessionContext(ctx, folderID, name, is.conflictFor(opts)); err != nil {
+			return nil, resp, conflictError(err, name)
+		}
+	}
+
+	save := func(session *UploadSession) error {
+		state, err := newUploadState(session, source)
+		if err != nil {
+			return err
+		}
+		return store.SaveUploadState(key, state)
+	}
+	if err := save(session); err != nil {
+		return nil, resp, err
+	}
+
+	item, resp, err := is.uploadToSession(ctx, session, file, source.Size, opts, save)
+	if err != nil {
+		return nil, resp, conflictError(err, name)
+	}
+
+	return item, resp, store.DeleteUploadState(key)
+}
+
+// resumeSession returns the session of a saved upload with the ranges the
+// service still expects, or nil if there is no session left to resume.
+func (is *ItemService) resumeSession(ctx context.Context, state *UploadState, source UploadSource) (*UploadSession, *http.Response, error) {
+	if state == nil || (!state.ExpirationDateTime.IsZero() && time.Now().After(state.ExpirationDateTime)) {
+		return nil, nil, nil
+	}
+	if !state.Source.equal(source) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUploadSourceChanged, source.Path)
+	}
+
+	session, resp, err := is.UploadSessionStatusContext(ctx, &UploadSession{UploadURL: state.UploadURL})
+	if errors.Is(err, ErrNotFound) {
+		return nil, resp, nil
+	}
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return session, resp, nil
+}
+
+// An UploadStateStore persists the state of uploads made by ResumeUpload.
+// Keys identify the destination of an upload; a missing key loads as nil.
+type UploadStateStore interface {
+	LoadUploadState(key string) (*UploadState, error)
+	SaveUploadState(key string, state *UploadState) error
+	DeleteUploadState(key string) error
+}
+
+// MemoryUploadStateStore keeps upload states in memory. The zero value is
+// ready to use.
+type MemoryUploadStateStore struct {
+	mu     sync.Mutex
+	states map[string]*UploadState
+}
+
+// LoadUploadState returns the state stored under key.
+func (s *MemoryUploadStateStore) LoadUploadState(key string) (*UploadState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[key], nil
+}
+
+// SaveUploadState stores state under key.
+func (s *MemoryUploadStateStore) SaveUploadState(key string, state *UploadState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.states == nil {
+		s.states = make(map[string]*UploadState)
+	}
+	s.states[key] = state
+	return nil
+}
+
+// DeleteUploadState removes the state stored under key.
+func (s *MemoryUploadStateStore) DeleteUploadState(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, key)
+	return nil
+}
+
+// FileUploadStateStore keeps upload states as a JSON object in a file.
+type FileUploadStateStore struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (s *FileUploadStateStore) load() (map[string]*UploadState, error) {
+	states := make(map[string]*UploadState)
+	b, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// save replaces the file atomically.
+func (s *FileUploadStateStore) save(states map[string]*UploadState) error {
+	b, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.Path, b, 0600)
+}
+
+// LoadUploadState returns the state stored under key.
+func (s *FileUploadStateStore) LoadUploadState(key string) (*UploadState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return states[key], nil
+}
+
+// SaveUploadState stores state under key.
+func (s *FileUploadStateStore) SaveUploadState(key string, state *UploadState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states, err := s.load()
+	if err != nil {
+		return err
+	}
+	states[key] = state
+	return s.save(states)
+}
+
+// DeleteUploadState removes the state stored under key.
+func (s *FileUploadStateStore) DeleteUploadState(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states, err := s.load()
+	if err != nil {
+		return err
+	}
+	delete(states, key)
+	return s.save(states)
+}
@@ -0,0 +1,163 @@
+package onedrive
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfirmedRanges(t *testing.T) {
+	tt := []struct {
+		expected    []string
+		size        int64
+		expectedOut []string
+	}{
+		{[]string{"0-"}, 100, []string{}},
+		{[]string{"40-"}, 100, []string{"0-39"}},
+		{[]string{"10-19", "50-"}, 100, []string{"0-9", "20-49"}},
+		{[]string{"50-59", "10-19"}, 100, []string{"0-9", "20-49", "60-99"}},
+		{nil, 100, []string{"0-99"}},
+	}
+	for i, tst := range tt {
+		got, err := confirmedRanges(tst.expected, tst.size)
+		if err != nil {
+			t.Errorf("[%d] %v", i, err)
+		}
+		if !reflect.DeepEqual(got, tst.expectedOut) {
+			t.Errorf("[%d] Got %v Expected %v", i, got, tst.expectedOut)
+		}
+	}
+}
+
+// largeFile writes size random bytes to a file named large.bin and opens it.
+func largeFile(t *testing.T, size int) ([]byte, *os.File) {
+	content := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(content)
+	path := filepath.Join(t.TempDir(), "large.bin")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return content, file
+}
+
+func TestResumeUpload(t *testing.T) {
+	setup()
+	defer teardown()
+
+	content, file := largeFile(t, 1048676)
+	ss := &sessionServer{failAt: 327680}
+	created := 0
+	mux.HandleFunc("/drive/root:/large.bin:/upload.createSession", func(w http.ResponseWriter, r *http.Request) {
+		created++
+		fileTemplateHandler("fixtures/session.created.json", http.StatusOK)(w, r)
+	})
+	mux.Handle("/upload/session1", ss)
+
+	// The first attempt stops at the failed fragment, as if interrupted.
+	store := &FileUploadStateStore{Path: filepath.Join(t.TempDir(), "uploads.json")}
+	opts := &UploadOptions{ChunkSize: 320 * 1024, FragmentAttempts: 1}
+	if _, _, err := oneDrive.Items.ResumeUpload("root", file, store, opts); err == nil {
+		t.Fatal("Expected the first attempt to fail")
+	}
+
+	state, err := store.LoadUploadState("/root/large.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state == nil {
+		t.Fatal("Expected the upload state to be saved")
+	}
+	if got, want := state.UploadURL, server.URL+"/upload/session1"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := state.ConfirmedRanges, []string{"0-327679"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+
+	// A new client, as after a restart, continues from the session.
+	oneDrive = NewOneDrive(http.DefaultClient, false)
+	oneDrive.BaseURL = server.URL
+	item, _, err := oneDrive.Items.ResumeUpload("root", file, store, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := item.Name, "large.bin"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if !bytes.Equal(ss.data, content) {
+		t.Error("Expected the uploaded content to match the file")
+	}
+	if got, want := created, 1; got != want {
+		t.Errorf("Got %d Expected %d sessions", got, want)
+	}
+	if state, _ := store.LoadUploadState("/root/large.bin"); state != nil {
+		t.Errorf("Expected the upload state to be deleted, got %v", state)
+	}
+}
+
+func TestResumeUploadSourceChanged(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, file := largeFile(t, 1024)
+	source, err := uploadSource(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		modify      func(*UploadSource)
+		expectedErr error
+	}{
+		{func(s *UploadSource) { s.Size++ }, ErrUploadSourceChanged},
+		{func(s *UploadSource) { s.ModTime = s.ModTime.Add(-time.Hour) }, ErrUploadSourceChanged},
+		{func(s *UploadSource) { s.Path += ".old" }, ErrUploadSourceChanged},
+	}
+	for i, tst := range tt {
+		saved := source
+		tst.modify(&saved)
+		store := new(MemoryUploadStateStore)
+		store.SaveUploadState("/root/large.bin", &UploadState{UploadURL: server.URL + "/upload/session1", Source: saved})
+
+		if _, _, err := oneDrive.Items.ResumeUpload("root", file, store, nil); !errors.Is(err, tst.expectedErr) {
+			t.Errorf("[%d] Got %v Expected %v", i, err, tst.expectedErr)
+		}
+	}
+}
+
+func TestResumeUploadExpiredSession(t *testing.T) {
+	setup()
+	defer teardown()
+
+	content, file := largeFile(t, 1024)
+	source, err := uploadSource(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ss := new(sessionServer)
+	mux.HandleFunc("/drive/root:/large.bin:/upload.createSession", fileTemplateHandler("fixtures/session.created.json", http.StatusOK))
+	mux.Handle("/upload/session1", ss)
+	mux.HandleFunc("/upload/expired", fileWrapperHandler("fixtures/request.invalid.notFound.json", http.StatusNotFound))
+
+	store := new(MemoryUploadStateStore)
+	store.SaveUploadState("/root/large.bin", &UploadState{UploadURL: server.URL + "/upload/expired", Source: source})
+
+	if _, _, err := oneDrive.Items.ResumeUpload("root", file, store, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ss.data, content) {
+		t.Error("Expected the file to be uploaded to a new session")
+	}
+}
@@ -0,0 +1,135 @@
+package onedrive
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how the client replays requests that failed with a
+// transient error. Throttled requests (429) are rejected by the service before
+// they are processed, so they are replayed regardless of method; other
+// retryable failures are only replayed for idempotent methods.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made, including the first.
+	MaxAttempts int
+	// BaseBackoff is the delay before the first retry. It is doubled for every
+	// subsequent retry, up to MaxBackoff, and jittered.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// MaxRetryAfter caps how long the client is willing to wait when the
+	// service responds with a Retry-After header. Longer waits are returned to
+	// the caller as errors instead.
+	MaxRetryAfter time.Duration
+	// RetryableStatus is the set of HTTP status codes which are retried.
+	RetryableStatus map[int]bool
+	// RetryTransportErrors controls whether network errors are retried.
+	RetryTransportErrors bool
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used by NewOneDrive.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:   4,
+		BaseBackoff:   500 * time.Millisecond,
+		MaxBackoff:    30 * time.Second,
+		MaxRetryAfter: 2 * time.Minute,
+		RetryableStatus: map[int]bool{
+			statusTooManyRequests:         true,
+			http.StatusServiceUnavailable: true,
+			http.StatusGatewayTimeout:     true,
+		},
+		RetryTransportErrors: true,
+	}
+}
+
+// isIdempotent reports whether a request with the given method can safely be
+// replayed after the service may have processed it.
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns the jittered delay to wait before the given retry attempt,
+// where the first retry is attempt 1.
+func (rp *RetryPolicy) backoff(attempt int) time.Duration {
+	d := rp.BaseBackoff
+	for i := 1; i < attempt && d < rp.MaxBackoff; i++ {
+		d *= 2
+	}
+	if rp.MaxBackoff > 0 && d > rp.MaxBackoff {
+		d = rp.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryDelay decides whether the outcome of an attempt should be retried and,
+// if so, how long to wait first.
+func (rp *RetryPolicy) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if rp == nil || attempt >= rp.MaxAttempts || req.Context().Err() != nil {
+		return 0, false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return 0, false
+	}
+
+	if resp == nil {
+		if err == nil || !rp.RetryTransportErrors || !isIdempotent(req.Method) {
+			return 0, false
+		}
+		return rp.backoff(attempt), true
+	}
+
+	if !rp.RetryableStatus[resp.StatusCode] {
+		return 0, false
+	}
+	if resp.StatusCode != statusTooManyRequests && !isIdempotent(req.Method) {
+		return 0, false
+	}
+
+	now := time.Now()
+	if until, err := calculateThrottle(now, resp.Header.Get("Retry-After")); err == nil {
+		wait := until.Sub(now)
+		if wait > rp.MaxRetryAfter {
+			return 0, false
+		}
+		return wait, true
+	}
+	return rp.backoff(attempt), true
+}
+
+// rewind prepares a request that has already been sent to be sent again.
+func rewind(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+// sleep waits for the duration d, returning early if the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
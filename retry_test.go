@@ -0,0 +1,169 @@
+package onedrive
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func fastRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.BaseBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	return policy
+}
+
+func TestIsIdempotent(t *testing.T) {
+	tt := []struct {
+		method string
+		out    bool
+	}{
+		{"GET", true},
+		{"PUT", true},
+		{"DELETE", true},
+		{"POST", false},
+		{"PATCH", false},
+	}
+	for i, tst := range tt {
+		if got, want := isIdempotent(tst.method), tst.out; got != want {
+			t.Errorf("[%d] Got %t Expected %t", i, got, want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := &RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	tt := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 150 * time.Millisecond, 300 * time.Millisecond},
+		{10, 150 * time.Millisecond, 300 * time.Millisecond},
+	}
+	for i, tst := range tt {
+		got := policy.backoff(tst.attempt)
+		if got < tst.min || got > tst.max {
+			t.Errorf("[%d] Got %s Expected between %s and %s", i, got, tst.min, tst.max)
+		}
+	}
+}
+
+func TestRetryServiceUnavailable(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.RetryPolicy = fastRetryPolicy()
+
+	attempts := 0
+	mux.HandleFunc("/drive", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			fileWrapperHandler("fixtures/request.invalid.serviceNotAvailable.json", http.StatusServiceUnavailable)(w, r)
+			return
+		}
+		fileWrapperHandler("fixtures/drive.valid.default.json", http.StatusOK)(w, r)
+	})
+
+	drive, _, err := oneDrive.Drives.GetDefault()
+	if err != nil {
+		t.Fatalf("Expected the request to be retried, got: %s", err)
+	}
+	if got, want := drive.ID, expectedDefaultDrive.ID; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := attempts, 3; got != want {
+		t.Errorf("Got %d Expected %d attempts", got, want)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.RetryPolicy = fastRetryPolicy()
+
+	attempts := 0
+	mux.HandleFunc("/drive", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		fileWrapperHandler("fixtures/request.invalid.serviceNotAvailable.json", http.StatusServiceUnavailable)(w, r)
+	})
+
+	if _, _, err := oneDrive.Drives.GetDefault(); err == nil {
+		t.Fatal("Expected serviceNotAvailable error but none occured")
+	}
+	if got, want := attempts, oneDrive.RetryPolicy.MaxAttempts; got != want {
+		t.Errorf("Got %d Expected %d attempts", got, want)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.RetryPolicy = fastRetryPolicy()
+
+	attempts := 0
+	mux.HandleFunc("/drive/items/some-id/action.copy", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		fileWrapperHandler("fixtures/request.invalid.serviceNotAvailable.json", http.StatusServiceUnavailable)(w, r)
+	})
+
+	if _, _, err := oneDrive.Items.Copy("some-id", "copy", ItemReference{ID: "parent"}); err == nil {
+		t.Fatal("Expected serviceNotAvailable error but none occured")
+	}
+	if got, want := attempts, 1; got != want {
+		t.Errorf("Got %d Expected %d attempts", got, want)
+	}
+}
+
+func TestRetryTooManyRequestsReplaysBody(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.RetryPolicy = fastRetryPolicy()
+
+	var bodies []string
+	mux.HandleFunc("/drive/items/some-id/action.copy", func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			panic(err)
+		}
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "0")
+			fileWrapperHandler("fixtures/request.invalid.tooManyRequests.json", statusTooManyRequests)(w, r)
+			return
+		}
+		w.Header().Set("Location", "/monitor/job")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	if _, _, err := oneDrive.Items.Copy("some-id", "copy", ItemReference{ID: "parent"}); err != nil {
+		t.Fatalf("Expected the request to be retried, got: %s", err)
+	}
+	if got, want := len(bodies), 2; got != want {
+		t.Fatalf("Got %d Expected %d attempts", got, want)
+	}
+	if bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Errorf("Got %q Expected %q", bodies[1], bodies[0])
+	}
+}
+
+func TestRetryDisabled(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.RetryPolicy = nil
+
+	attempts := 0
+	mux.HandleFunc("/drive", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		fileWrapperHandler("fixtures/request.invalid.serviceNotAvailable.json", http.StatusServiceUnavailable)(w, r)
+	})
+
+	if _, _, err := oneDrive.Drives.GetDefault(); err == nil {
+		t.Fatal("Expected serviceNotAvailable error but none occured")
+	}
+	if got, want := attempts, 1; got != want {
+		t.Errorf("Got %d Expected %d attempts", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package onedrive
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// searchURI returns the request URI to search the hierarchy under itemID for
+// text. The legacy API takes the text as a parameter of view.search, Graph as
+// an argument of the search function.
+func (is *ItemService) searchURI(itemID, text string) string {
+	if is.Graph {
+		escaped := url.PathEscape(strings.Replace(text, "'", "''", -1))
+		return is.itemURI(itemID) + "/search(q='" + escaped + "')"
+	}
+	return is.itemURI(itemID) + "/view.search?q=" + escapeOption(text)
+}
+
+// Search returns the first page of Items under itemID whose name, metadata or
+// content match text. Each result has its SearchResult facet set. Use
+// SearchPager to fetch every page.
+// See: http://onedrive.github.io/items/search.htm
+func (is *ItemService) Search(itemID, text string) (*Items, *http.Response, error) {
+	return is.SearchContext(context.Background(), itemID, text)
+}
+
+// SearchContext is like Search but carries a context and accepts query options.
+func (is *ItemService) SearchContext(ctx context.Context, itemID, text string, query ...*Query) (*Items, *http.Response, error) {
+	uri, err := withQuery(is.searchURI(itemID, text), itemType, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := is.newRequest(ctx, "GET", uri, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := new(Items)
+	resp, err := is.do(req, items)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return items, resp, nil
+}
+
+// SearchPager returns a Pager over every Item under itemID matching text.
+func (is *ItemService) SearchPager(itemID, text string, query ...*Query) *Pager[*Item] {
+	uri, err := withQuery(is.searchURI(itemID, text), itemType, query)
+	return newPager[*Item](is.OneDrive, uri, err)
+}
@@ -0,0 +1,82 @@
+package onedrive
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func searchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("$skiptoken") == "page2" {
+		fileTemplateHandler("fixtures/item.search.page2.json", http.StatusOK)(w, r)
+		return
+	}
+	fileTemplateHandler("fixtures/item.search.valid.json", http.StatusOK)(w, r)
+}
+
+func TestSearchURI(t *testing.T) {
+	tt := []struct {
+		graph        bool
+		itemID, text string
+		expectedOut  string
+	}{
+		{false, "root", "q3 report", "/drive/root/view.search?q=q3%20report"},
+		{false, "123", "a&b", "/drive/items/123/view.search?q=a%26b"},
+		{true, "root", "q3 report", "/drive/root/search(q='q3%20report')"},
+		{true, "root", "it's", "/drive/root/search(q='it%27%27s')"},
+	}
+	for i, tst := range tt {
+		od := NewOneDrive(http.DefaultClient, false)
+		od.Graph = tst.graph
+		if got, want := od.Items.searchURI(tst.itemID, tst.text), tst.expectedOut; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var rawQuery string
+	mux.HandleFunc("/drive/root/view.search", func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		searchHandler(w, r)
+	})
+
+	items, _, err := oneDrive.Items.SearchContext(context.Background(), "root", "report", NewQuery().Select("id", "name", "searchResult").OrderBy("name", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rawQuery, "q=report&$select=id,name,searchResult&$orderby=name"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := len(items.Collection), 1; got != want {
+		t.Fatalf("Got %d Expected %d", got, want)
+	}
+	if got, want := items.Collection[0].SearchResult, (&SearchResultFacet{"https://bing.com/0123456789abc!120"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+	if items.NextLink == "" {
+		t.Error("Expected a link to the next page")
+	}
+}
+
+func TestSearchPager(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/root/view.search", searchHandler)
+
+	var names []string
+	for item, err := range oneDrive.Items.SearchPager("root", "report").All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, item.Name)
+	}
+	if got, want := names, []string{"Q3 report.xlsx", "Q4 report.xlsx"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v Expected %v", got, want)
+	}
+}
@@ -0,0 +1,402 @@
+package onedrive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Fragments uploaded to a session must be a multiple of 320KiB in size,
+	// apart from the last one, and no larger than 60MiB.
+	uploadFragmentUnit = 320 * 1024
+	defaultChunkSize   = 32 * uploadFragmentUnit
+	maxChunkSize       = 192 * uploadFragmentUnit
+)
+
+// UploadOptions configures uploads made through an upload session. The zero
+// value uses the defaults described on each field.
+type UploadOptions struct {
+	// ConflictBehaviour decides what happens if an item with the same name
+	// already exists. When empty, the behaviour set with
+	// ItemService.OnConflict is used, if any.
+	ConflictBehaviour ConflictBehaviour
+	// ChunkSize is the size of the fragments the file is uploaded in, 10MiB
+	// when zero. It is rounded down to a multiple of 320KiB and capped at
+	// 60MiB.
+	ChunkSize int64
+	// FragmentAttempts is the number of times in a row a fragment is
+	// attempted before the upload fails, 3 when zero.
+	FragmentAttempts int
+	// SimpleUploadThreshold is the size below which Upload sends content in a
+	// single request rather than through an upload session, 4MiB when zero.
+	// It is capped at 100MB, the largest simple upload.
+	SimpleUploadThreshold int64
+	// SpoolLimit is the most content of unknown size Upload copies to a
+	// temporary file to learn its size, 1GiB when zero. A negative limit
+	// disables spooling.
+	SpoolLimit int64
+	// SpoolDir is the directory Upload creates its temporary file in, the
+	// default directory for temporary files when empty.
+	SpoolDir string
+}
+
+// conflictFor returns the conflict behaviour of an upload made with opts.
+func (is *ItemService) conflictFor(opts *UploadOptions) ConflictBehaviour {
+	if opts != nil && opts.ConflictBehaviour != "" {
+		return opts.ConflictBehaviour
+	}
+	return is.conflict
+}
+
+func (uo *UploadOptions) chunkSize() int64 {
+	if uo == nil || uo.ChunkSize <= 0 {
+		return defaultChunkSize
+	}
+	size := uo.ChunkSize - uo.ChunkSize%uploadFragmentUnit
+	switch {
+	case size < uploadFragmentUnit:
+		return uploadFragmentUnit
+	case size > maxChunkSize:
+		return maxChunkSize
+	}
+	return size
+}
+
+func (uo *UploadOptions) simpleUploadThreshold() int64 {
+	if uo == nil || uo.SimpleUploadThreshold <= 0 {
+		return 4 << 20
+	}
+	if uo.SimpleUploadThreshold > oneHundredMB {
+		return oneHundredMB
+	}
+	return uo.SimpleUploadThreshold
+}
+
+func (uo *UploadOptions) spoolLimit() int64 {
+	if uo == nil || uo.SpoolLimit == 0 {
+		return 1 << 30
+	}
+	return uo.SpoolLimit
+}
+
+func (uo *UploadOptions) spoolDir() string {
+	if uo == nil {
+		return ""
+	}
+	return uo.SpoolDir
+}
+
+func (uo *UploadOptions) fragmentAttempts() int {
+	if uo == nil || uo.FragmentAttempts <= 0 {
+		return 3
+	}
+	return uo.FragmentAttempts
+}
+
+// UploadSession is a session created with CreateUploadSession. Its
+// pre-authenticated UploadURL accepts the fragments of a file until the
+// session expires.
+// See: http://onedrive.github.io/items/upload_large_files.htm
+type UploadSession struct {
+	UploadURL          string    `json:"uploadUrl"`
+	ExpirationDateTime time.Time `json:"expirationDateTime"`
+	// NextExpectedRanges lists the ranges of the file which the service has
+	// not received yet, such as "0-" or "327680-655359".
+	NextExpectedRanges []string `json:"nextExpectedRanges"`
+}
+
+// nextRange returns the first range of bytes the service is waiting for. A
+// negative end means the rest of the file.
+func (us *UploadSession) nextRange() (start, end int64, err error) {
+	if len(us.NextExpectedRanges) == 0 {
+		return 0, -1, nil
+	}
+
+	bounds := strings.SplitN(us.NextExpectedRanges[0], "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("onedrive: malformed expected range %q", us.NextExpectedRanges[0])
+	}
+	if start, err = strconv.ParseInt(bounds[0], 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("onedrive: malformed expected range %q", us.NextExpectedRanges[0])
+	}
+	if bounds[1] == "" {
+		return start, -1, nil
+	}
+	if end, err = strconv.ParseInt(bounds[1], 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("onedrive: malformed expected range %q", us.NextExpectedRanges[0])
+	}
+	return start, end, nil
+}
+
+type newUploadSession struct {
+	Item uploadSessionItem `json:"item"`
+}
+
+type uploadSessionItem struct {
+	ConflictBehaviour      ConflictBehaviour `json:"@name.conflictBehavior,omitempty"`
+	GraphConflictBehaviour ConflictBehaviour `json:"@microsoft.graph.conflictBehavior,omitempty"`
+}
+
+// CreateUploadSession creates an upload session for a file named name within
+// the folder with the given parentID. An empty conflict leaves the conflict
+// behaviour to the service.
+// See: http://onedrive.github.io/items/upload_large_files.htm
+func (is *ItemService) CreateUploadSession(parentID, name string, conflict ConflictBehaviour) (*UploadSession, *http.Response, error) {
+	return is.CreateUploadSessionContext(context.Background(), parentID, name, conflict)
+}
+
+// CreateUploadSessionContext is like CreateUploadSession but carries a context.
+func (is *ItemService) CreateUploadSessionContext(ctx context.Context, parentID, name string, conflict ConflictBehaviour) (*UploadSession, *http.Response, error) {
+	return is.createUploadSession(ctx, fmt.Sprintf("%s:/%s:", is.itemURI(parentID), url.PathEscape(name)), conflict)
+}
+
+// createUploadSession creates an upload session for the item at the given
+// request URI.
+func (is *ItemService) createUploadSession(ctx context.Context, uri string, conflict ConflictBehaviour) (*UploadSession, *http.Response, error) {
+	body := new(newUploadSession)
+	if is.Graph {
+		uri += "/createUploadSession"
+		body.Item.GraphConflictBehaviour = conflict
+	} else {
+		uri += "/upload.createSession"
+		body.Item.ConflictBehaviour = conflict
+	}
+
+	req, err := is.newRequest(ctx, "POST", uri, nil, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session := new(UploadSession)
+	resp, err := is.do(req, session)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return session, resp, nil
+}
+
+// UploadSessionStatus returns the session as currently known to the service,
+// including the ranges it still expects.
+func (is *ItemService) UploadSessionStatus(session *UploadSession) (*UploadSession, *http.Response, error) {
+	return is.UploadSessionStatusContext(context.Background(), session)
+}
+
+// UploadSessionStatusContext is like UploadSessionStatus but carries a context.
+func (is *ItemService) UploadSessionStatusContext(ctx context.Context, session *UploadSession) (*UploadSession, *http.Response, error) {
+	req, err := is.newRequest(ctx, "GET", session.UploadURL, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	status := &UploadSession{UploadURL: session.UploadURL}
+	resp, err := is.doWith(is.preauthClient(), req, status)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return status, resp, nil
+}
+
+// CancelUploadSession cancels the session and discards the fragments which
+// have been uploaded to it.
+func (is *ItemService) CancelUploadSession(session *UploadSession) (*http.Response, error) {
+	return is.CancelUploadSessionContext(context.Background(), session)
+}
+
+// CancelUploadSessionContext is like CancelUploadSession but carries a context.
+func (is *ItemService) CancelUploadSessionContext(ctx context.Context, session *UploadSession) (*http.Response, error) {
+	req, err := is.newRequest(ctx, "DELETE", session.UploadURL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return is.doWith(is.preauthClient(), req, nil)
+}
+
+// UploadFragment uploads the length bytes read from r as the fragment of a
+// size byte file starting at offset. Sessions need the size of the file with
+// every fragment, so a negative size returns ErrUnknownSize. Once the last
+// fragment has been received the created item is returned; until then the
+// item is nil and the session's NextExpectedRanges is updated. If r is an
+// io.ReadSeeker, the fragment can be retried after a transient failure.
+func (is *ItemService) UploadFragment(session *UploadSession, r io.Reader, offset, length, size int64) (*Item, *http.Response, error) {
+	return is.UploadFragmentContext(context.Background(), session, r, offset, length, size)
+}
+
+// UploadFragmentContext is like UploadFragment but carries a context.
+func (is *ItemService) UploadFragmentContext(ctx context.Context, session *UploadSession, r io.Reader, offset, length, size int64) (*Item, *http.Response, error) {
+	if size < 0 {
+		return nil, nil, ErrUnknownSize
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", session.UploadURL, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if seeker, ok := r.(io.ReadSeeker); ok && req.GetBody == nil {
+		if err := seekableBody(req, seeker); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The upload URL is pre-authenticated, so the request is sent without
+	// credentials.
+	req.ContentLength = length
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, size))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("User-Agent", userAgent)
+
+	var body json.RawMessage
+	resp, err := is.doWith(is.preauthClient(), req, &body)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if resp.StatusCode == http.StatusAccepted {
+		if err := json.Unmarshal(body, session); err != nil {
+			return nil, resp, err
+		}
+		return nil, resp, nil
+	}
+
+	item := new(Item)
+	if err := json.Unmarshal(body, item); err != nil {
+		return nil, resp, err
+	}
+
+	return item, resp, nil
+}
+
+// ResumableUpload uploads file to the folder with the given folderID through
+// an upload session, which supports files of any size. The file is sent in
+// fragments; when one fails, the session is asked which ranges it still
+// expects and the upload continues from there. The session is left open if
+// the upload fails.
+// See: http://onedrive.github.io/items/upload_large_files.htm
+func (is *ItemService) ResumableUpload(folderID string, file *os.File, opts *UploadOptions) (*Item, *http.Response, error) {
+	return is.ResumableUploadContext(context.Background(), folderID, file, opts)
+}
+
+// ResumableUploadContext is like ResumableUpload but carries a context.
+func (is *ItemService) ResumableUploadContext(ctx context.Context, folderID string, file *os.File, opts *UploadOptions) (*Item, *http.Response, error) {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := filepath.Base(file.Name())
+	if fileInfo.Size() == 0 {
+		// Sessions do not accept empty fragments.
+		return is.uploadContent(ctx, is.childContentURI(folderID, name), name, file)
+	}
+
+	session, resp, err := is.CreateUploadSessionContext(ctx, folderID, name, is.conflictFor(opts))
+	if err != nil {
+		return nil, resp, conflictError(err, name)
+	}
+
+	item, resp, err := is.uploadToSession(ctx, session, file, fileInfo.Size(), opts, nil)
+	return item, resp, conflictError(err, name)
+}
+
+// uploadToSession uploads the size bytes of r to the session, fragment by
+// fragment, starting from the ranges the session expects. If accepted is not
+// nil it is called with the session after every fragment the service accepts.
+func (is *ItemService) uploadToSession(ctx context.Context, session *UploadSession, r io.ReaderAt, size int64, opts *UploadOptions, accepted func(*UploadSession) error) (*Item, *http.Response, error) {
+	var resp *http.Response
+	failures := 0
+	for {
+		start, end, err := session.nextRange()
+		if err != nil {
+			return nil, resp, err
+		}
+
+		length := opts.chunkSize()
+		if end >= 0 && end-start+1 < length {
+			length = end - start + 1
+		}
+		if start+length > size {
+			length = size - start
+		}
+		if length <= 0 {
+			return nil, resp, fmt.Errorf("onedrive: upload session expects byte %d of a %d byte file", start, size)
+		}
+
+		var item *Item
+		item, resp, err = is.UploadFragmentContext(ctx, session, io.NewSectionReader(r, start, length), start, length, size)
+		if err == nil {
+			if item != nil {
+				return is.verifyUpload(item, resp, r, size)
+			}
+			if accepted != nil {
+				if err := accepted(session); err != nil {
+					return nil, resp, err
+				}
+			}
+			failures = 0
+			continue
+		}
+
+		failures++
+		if resp, err := is.recoverSession(ctx, session, err, failures, opts); err != nil {
+			return nil, resp, err
+		}
+	}
+}
+
+// verifyUpload checks the size bytes of r against the hashes of the uploaded
+// item if VerifyHashes is set.
+func (is *ItemService) verifyUpload(item *Item, resp *http.Response, r io.ReaderAt, size int64) (*Item, *http.Response, error) {
+	if !is.VerifyHashes {
+		return item, resp, nil
+	}
+
+	local, err := hashReaderAt(r, size)
+	if err != nil {
+		return nil, resp, err
+	}
+	if err := verifyHashes(itemHashes(item), local); err != nil {
+		return nil, resp, err
+	}
+
+	return item, resp, nil
+}
+
+// recoverSession decides whether an upload can go on after a fragment failed
+// with err for the given number of times in a row. If it can, the session is
+// refreshed with the ranges the service still expects, since part of the
+// fragment may have been received.
+func (is *ItemService) recoverSession(ctx context.Context, session *UploadSession, err error, failures int, opts *UploadOptions) (*http.Response, error) {
+	// An expired session is reported as not found and cannot be resumed,
+	// and a name conflict found on completion does not go away either.
+	if failures >= opts.fragmentAttempts() || ctx.Err() != nil ||
+		errors.Is(err, ErrNotFound) || errors.Is(err, ErrNameAlreadyExists) {
+		return nil, err
+	}
+	if is.RetryPolicy != nil {
+		if err := sleep(ctx, is.RetryPolicy.backoff(failures)); err != nil {
+			return nil, err
+		}
+	}
+
+	status, resp, err := is.UploadSessionStatusContext(ctx, session)
+	if err != nil {
+		return resp, err
+	}
+	session.NextExpectedRanges = status.NextExpectedRanges
+	if !status.ExpirationDateTime.IsZero() {
+		session.ExpirationDateTime = status.ExpirationDateTime
+	}
+	return resp, nil
+}
@@ -0,0 +1,238 @@
+package onedrive
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// sessionServer fakes the upload URL of an upload session. The first
+// fragment starting at failAt is only half received before the request fails.
+type sessionServer struct {
+	mu            sync.Mutex
+	data          []byte
+	failAt        int64
+	failed        bool
+	contentRanges []string
+	authorization []string
+	cancelled     bool
+}
+
+func (ss *sessionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	next := func() {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"expirationDateTime": "2099-01-29T09:21:55.523Z",
+			"nextExpectedRanges": []string{fmt.Sprintf("%d-", len(ss.data))},
+		})
+	}
+
+	switch r.Method {
+	case "GET":
+		next()
+	case "DELETE":
+		ss.cancelled = true
+		w.WriteHeader(http.StatusNoContent)
+	case "PUT":
+		ss.contentRanges = append(ss.contentRanges, r.Header.Get("Content-Range"))
+		ss.authorization = append(ss.authorization, r.Header.Get("Authorization"))
+
+		var start, end, size int64
+		fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &size)
+		if start != int64(len(ss.data)) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		if start == ss.failAt && !ss.failed {
+			ss.failed = true
+			ss.data = append(ss.data, b[:len(b)/2]...)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		ss.data = append(ss.data, b...)
+
+		if int64(len(ss.data)) == size {
+			fileWrapperHandler("fixtures/item.upload.large.json", http.StatusCreated)(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		next()
+	}
+}
+
+func TestUploadOptionsChunkSize(t *testing.T) {
+	tt := []struct {
+		opts        *UploadOptions
+		expectedOut int64
+	}{
+		{nil, 10 << 20},
+		{&UploadOptions{}, 10 << 20},
+		{&UploadOptions{ChunkSize: 700 * 1024}, 640 * 1024},
+		{&UploadOptions{ChunkSize: 1}, 320 * 1024},
+		{&UploadOptions{ChunkSize: 1 << 30}, 60 << 20},
+	}
+	for i, tst := range tt {
+		if got, want := tst.opts.chunkSize(), tst.expectedOut; got != want {
+			t.Errorf("[%d] Got %d Expected %d", i, got, want)
+		}
+	}
+}
+
+func TestUploadSessionNextRange(t *testing.T) {
+	tt := []struct {
+		ranges     []string
+		start, end int64
+		err        bool
+	}{
+		{nil, 0, -1, false},
+		{[]string{"0-"}, 0, -1, false},
+		{[]string{"327680-655359", "983040-"}, 327680, 655359, false},
+		{[]string{"x-"}, 0, 0, true},
+		{[]string{"12"}, 0, 0, true},
+	}
+	for i, tst := range tt {
+		start, end, err := (&UploadSession{NextExpectedRanges: tst.ranges}).nextRange()
+		if got, want := err != nil, tst.err; got != want {
+			t.Errorf("[%d] Got %v Expected an error: %t", i, err, want)
+		}
+		if start != tst.start || end != tst.end {
+			t.Errorf("[%d] Got %d-%d Expected %d-%d", i, start, end, tst.start, tst.end)
+		}
+	}
+}
+
+func TestCreateUploadSession(t *testing.T) {
+	tt := []struct {
+		graph        bool
+		expectedPath string
+		expectedBody string
+	}{
+		{false, "/drive/items/101:/q3 report.xlsx:/upload.createSession", `{"item":{"@name.conflictBehavior":"rename"}}`},
+		{true, "/me/drive/items/101:/q3 report.xlsx:/createUploadSession", `{"item":{"@microsoft.graph.conflictBehavior":"rename"}}`},
+	}
+	for i, tst := range tt {
+		setup()
+		var path, body string
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			b, _ := io.ReadAll(r.Body)
+			body = string(bytes.TrimSpace(b))
+			fileTemplateHandler("fixtures/session.created.json", http.StatusOK)(w, r)
+		})
+		oneDrive.Graph = tst.graph
+
+		session, _, err := oneDrive.Items.CreateUploadSession("101", "q3 report.xlsx", ConflictRename)
+		if err != nil {
+			t.Fatalf("[%d] %v", i, err)
+		}
+		if got, want := path, tst.expectedPath; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := body, tst.expectedBody; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := session.UploadURL, server.URL+"/upload/session1"; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		teardown()
+	}
+}
+
+func TestResumableUpload(t *testing.T) {
+	setup()
+	defer teardown()
+
+	content := make([]byte, 1048676)
+	rand.New(rand.NewSource(1)).Read(content)
+	path := filepath.Join(t.TempDir(), "large.bin")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	ss := &sessionServer{failAt: 327680}
+	mux.HandleFunc("/drive/root:/large.bin:/upload.createSession", fileTemplateHandler("fixtures/session.created.json", http.StatusOK))
+	mux.Handle("/upload/session1", ss)
+	oneDrive.Client = &http.Client{Transport: bearerTransport{}}
+	oneDrive.RetryPolicy = fastRetryPolicy()
+
+	item, _, err := oneDrive.Items.ResumableUpload("root", file, &UploadOptions{ChunkSize: 320 * 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := item.Name, "large.bin"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if !bytes.Equal(ss.data, content) {
+		t.Error("Expected the uploaded content to match the file")
+	}
+
+	// The second fragment is resumed from where the service stopped
+	// receiving it.
+	expected := []string{
+		"bytes 0-327679/1048676",
+		"bytes 327680-655359/1048676",
+		"bytes 491520-819199/1048676",
+		"bytes 819200-1048675/1048676",
+	}
+	if got := ss.contentRanges; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got %v Expected %v", got, expected)
+	}
+	for _, authorization := range ss.authorization {
+		if authorization != "" {
+			t.Errorf("Expected fragments to be sent without credentials, got %q", authorization)
+		}
+	}
+}
+
+func TestResumableUploadFails(t *testing.T) {
+	setup()
+	defer teardown()
+
+	file, err := os.CreateTemp(t.TempDir(), "large.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	file.Write([]byte(downloadContent))
+	file.Seek(0, io.SeekStart)
+
+	mux.HandleFunc("/", fileTemplateHandler("fixtures/session.created.json", http.StatusOK))
+	mux.HandleFunc("/upload/session1", fileWrapperHandler("fixtures/request.invalid.notFound.json", http.StatusNotFound))
+
+	if _, _, err := oneDrive.Items.ResumableUpload("root", file, nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Got %v Expected %v", err, ErrNotFound)
+	}
+}
+
+func TestCancelUploadSession(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ss := new(sessionServer)
+	mux.Handle("/upload/session1", ss)
+
+	if _, err := oneDrive.Items.CancelUploadSession(&UploadSession{UploadURL: server.URL + "/upload/session1"}); err != nil {
+		t.Fatal(err)
+	}
+	if !ss.cancelled {
+		t.Error("Expected the session to be cancelled")
+	}
+}
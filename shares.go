@@ -0,0 +1,142 @@
+package onedrive
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// SharesService manages the communication with the shares API endpoints,
+// which give access to items through a share ID or a sharing URL.
+type SharesService struct {
+	*OneDrive
+}
+
+// The Share resource represents a shared item, or collection of items,
+// accessed through a share ID or sharing URL.
+// See: http://onedrive.github.io/resources/share.htm
+type Share struct {
+	ID    string       `json:"id"`
+	Name  string       `json:"name"`
+	Owner *IdentitySet `json:"owner"`
+	// Relationships
+	Root  *Item   `json:"root"`
+	Items []*Item `json:"items"`
+	// DriveItem is the shared item, it is only available through Graph.
+	DriveItem *Item `json:"driveItem"`
+}
+
+// EncodeSharingURL encodes a sharing URL into the share token accepted by the
+// shares API in place of a share ID: the URL is base64url encoded without
+// padding and prefixed with "u!".
+// See: http://onedrive.github.io/shares/shares_get.htm#encoding-sharing-urls
+func EncodeSharingURL(sharingURL string) string {
+	return "u!" + base64.RawURLEncoding.EncodeToString([]byte(sharingURL))
+}
+
+// shareURI returns the request URI of the share identified by shareIDOrURL,
+// which is either a share ID, a share token or a sharing URL.
+func shareURI(shareIDOrURL string) string {
+	if strings.HasPrefix(shareIDOrURL, "https://") || strings.HasPrefix(shareIDOrURL, "http://") {
+		shareIDOrURL = EncodeSharingURL(shareIDOrURL)
+	}
+	return "/shares/" + shareIDOrURL
+}
+
+// Get returns the share identified by shareIDOrURL, which is either a share
+// ID, a share token or a sharing URL. Use the expand query to include the
+// shared items.
+// See: http://onedrive.github.io/shares/shares_get.htm
+func (ss *SharesService) Get(shareIDOrURL string) (*Share, *http.Response, error) {
+	return ss.GetContext(context.Background(), shareIDOrURL)
+}
+
+// GetContext is like Get but carries a context and accepts query options.
+func (ss *SharesService) GetContext(ctx context.Context, shareIDOrURL string, query ...*Query) (*Share, *http.Response, error) {
+	uri, err := withQuery(shareURI(shareIDOrURL), shareType, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := ss.newRequest(ctx, "GET", uri, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	share := new(Share)
+	resp, err := ss.do(req, share)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return share, resp, nil
+}
+
+// Root returns the item at the root of the share, which for a shared folder
+// is the folder itself.
+func (ss *SharesService) Root(shareIDOrURL string) (*Item, *http.Response, error) {
+	return ss.RootContext(context.Background(), shareIDOrURL)
+}
+
+// RootContext is like Root but carries a context and accepts query options.
+func (ss *SharesService) RootContext(ctx context.Context, shareIDOrURL string, query ...*Query) (*Item, *http.Response, error) {
+	return ss.Items.getItem(ctx, shareURI(shareIDOrURL)+"/root", query)
+}
+
+// Item returns the shared item. Graph exposes it as the share's driveItem,
+// the legacy API only as the root of the share.
+func (ss *SharesService) Item(shareIDOrURL string) (*Item, *http.Response, error) {
+	return ss.ItemContext(context.Background(), shareIDOrURL)
+}
+
+// ItemContext is like Item but carries a context and accepts query options.
+func (ss *SharesService) ItemContext(ctx context.Context, shareIDOrURL string, query ...*Query) (*Item, *http.Response, error) {
+	if ss.Graph {
+		return ss.Items.getItem(ctx, shareURI(shareIDOrURL)+"/driveItem", query)
+	}
+	return ss.RootContext(ctx, shareIDOrURL, query...)
+}
+
+// Children returns the children of a shared folder.
+func (ss *SharesService) Children(shareIDOrURL string) (*Items, *http.Response, error) {
+	return ss.ChildrenContext(context.Background(), shareIDOrURL)
+}
+
+// ChildrenContext is like Children but carries a context and accepts query options.
+func (ss *SharesService) ChildrenContext(ctx context.Context, shareIDOrURL string, query ...*Query) (*Items, *http.Response, error) {
+	return ss.Items.listChildren(ctx, shareURI(shareIDOrURL)+"/root", query)
+}
+
+// Resolve fetches the shared item and returns an ItemService addressing the
+// drive it lives in, so that any ItemService operation can be applied to it
+// using the returned item's ID, e.g.
+//
+//	items, item, _, err := od.Shares.Resolve(sharingURL)
+//	children, _, err := items.ListChildren(item.ID)
+func (ss *SharesService) Resolve(shareIDOrURL string) (*ItemService, *Item, *http.Response, error) {
+	return ss.ResolveContext(context.Background(), shareIDOrURL)
+}
+
+// ResolveContext is like Resolve but carries a context.
+func (ss *SharesService) ResolveContext(ctx context.Context, shareIDOrURL string) (*ItemService, *Item, *http.Response, error) {
+	item, resp, err := ss.ItemContext(ctx, shareIDOrURL)
+	if err != nil {
+		return nil, nil, resp, err
+	}
+
+	items, itemID := ss.Items.ForItem(item)
+	if items.driveID == "" {
+		return nil, nil, resp, errors.New("onedrive: shared item does not reference its drive")
+	}
+	if itemID != item.ID {
+		// The share resolved to a shortcut, fetch the item it points to.
+		item, resp, err = items.getItem(ctx, items.itemURI(itemID), nil)
+		if err != nil {
+			return nil, nil, resp, err
+		}
+	}
+
+	return items, item, resp, nil
+}
@@ -0,0 +1,169 @@
+package onedrive
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+const sharingURL = "https://onedrive.live.com/redir?resid=1231244193912!12&authKey=1201919!12921!1"
+
+func TestEncodeSharingURL(t *testing.T) {
+	tt := []struct {
+		url      string
+		expected string
+	}{
+		{sharingURL, "u!aHR0cHM6Ly9vbmVkcml2ZS5saXZlLmNvbS9yZWRpcj9yZXNpZD0xMjMxMjQ0MTkzOTEyITEyJmF1dGhLZXk9MTIwMTkxOSExMjkyMSEx"},
+		{"https://1drv.ms/f/s!AmDGm8W_gS9ih1A?e=x>y", "u!aHR0cHM6Ly8xZHJ2Lm1zL2YvcyFBbURHbThXX2dTOWloMUE_ZT14Pnk"},
+	}
+	for i, tst := range tt {
+		if got, want := EncodeSharingURL(tst.url), tst.expected; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestShareRequests(t *testing.T) {
+	token := EncodeSharingURL(sharingURL)
+	tt := []struct {
+		graph   bool
+		call    func(ss *SharesService) error
+		path    string
+		query   string
+		fixture string
+	}{
+		{false, func(ss *SharesService) error {
+			_, _, err := ss.GetContext(context.Background(), sharingURL, NewQuery().Expand("items", nil))
+			return err
+		}, "/shares/" + token, "$expand=items", "fixtures/share.valid.json"},
+		{false, func(ss *SharesService) error {
+			_, _, err := ss.Get("s!AmDGm8W_gS9ih1A")
+			return err
+		}, "/shares/s!AmDGm8W_gS9ih1A", "", "fixtures/share.valid.json"},
+		{false, func(ss *SharesService) error {
+			_, _, err := ss.Root(sharingURL)
+			return err
+		}, "/shares/" + token + "/root", "", "fixtures/share.root.json"},
+		{false, func(ss *SharesService) error {
+			_, _, err := ss.Item(sharingURL)
+			return err
+		}, "/shares/" + token + "/root", "", "fixtures/share.root.json"},
+		{true, func(ss *SharesService) error {
+			_, _, err := ss.Item(sharingURL)
+			return err
+		}, "/shares/" + token + "/driveItem", "", "fixtures/share.root.json"},
+		{true, func(ss *SharesService) error {
+			_, _, err := ss.ChildrenContext(context.Background(), sharingURL, NewQuery().Top(5))
+			return err
+		}, "/shares/" + token + "/root/children", "$top=5", "fixtures/item.children.valid.json"},
+	}
+	for i, tst := range tt {
+		setup()
+		oneDrive.Graph = tst.graph
+		var path, query string
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			path, query = r.URL.Path, r.URL.RawQuery
+			fileWrapperHandler(tst.fixture, http.StatusOK)(w, r)
+		})
+
+		if err := tst.call(oneDrive.Shares); err != nil {
+			t.Errorf("[%d] %s", i, err)
+		}
+		teardown()
+
+		if got, want := path, tst.path; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := query, tst.query; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
+
+func TestGetShareInvalidQuery(t *testing.T) {
+	setup()
+	defer teardown()
+
+	if _, _, err := oneDrive.Shares.GetContext(context.Background(), sharingURL, NewQuery().Select("size")); err == nil {
+		t.Error("Expected an error for a field which shares do not have")
+	}
+}
+
+func TestResolveShare(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/shares/"+EncodeSharingURL(sharingURL)+"/root", fileWrapperHandler("fixtures/share.root.json", http.StatusOK))
+	mux.HandleFunc("/drives/fedcba9876543/items/fedcba9876543!320/children", fileWrapperHandler("fixtures/item.children.valid.json", http.StatusOK))
+
+	items, item, _, err := oneDrive.Shares.Resolve(sharingURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := items.DriveID(), "fedcba9876543"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := item.ID, "fedcba9876543!320"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if _, _, err := items.ListChildren(item.ID); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResolveShareRemoteItem(t *testing.T) {
+	setup()
+	defer teardown()
+	oneDrive.Graph = true
+
+	mux.HandleFunc("/shares/s!AmDGm8W_gS9ih1A/driveItem", fileWrapperHandler("fixtures/share.remote.json", http.StatusOK))
+	mux.HandleFunc("/drives/fedcba9876543/items/fedcba9876543!310", fileWrapperHandler("fixtures/share.root.json", http.StatusOK))
+
+	items, item, _, err := oneDrive.Shares.Resolve("s!AmDGm8W_gS9ih1A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := items.DriveID(), "fedcba9876543"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+	if got, want := item.Name, "Holiday"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestResolveShareWithoutDrive(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/shares/s!AmDGm8W_gS9ih1A/root", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "fedcba9876543!320", "name": "Holiday"}`))
+	})
+
+	if _, _, _, err := oneDrive.Shares.Resolve("s!AmDGm8W_gS9ih1A"); err == nil {
+		t.Error("Expected an error for a shared item without a drive")
+	}
+}
+
+func TestForItem(t *testing.T) {
+	setup()
+	defer teardown()
+
+	tt := []struct {
+		item            *Item
+		expectedDriveID string
+		expectedID      string
+	}{
+		{&Item{ID: "0123456789abc!200", RemoteItem: &RemoteItemFacet{ID: "fedcba9876543!310", ParentReference: &ItemReference{DriveID: "fedcba9876543"}}}, "fedcba9876543", "fedcba9876543!310"},
+		{&Item{ID: "b!item", ParentReference: &ItemReference{DriveID: "b!business"}}, "b!business", "b!item"},
+		{&Item{ID: "local"}, "other", "local"},
+	}
+	for i, tst := range tt {
+		items, id := oneDrive.Items.ForDrive("other").ForItem(tst.item)
+		if got, want := items.DriveID(), tst.expectedDriveID; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := id, tst.expectedID; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package onedrive
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Names of the special folders which can be resolved with
+// DriveService.Special.
+// See: http://onedrive.github.io/drives/get_special.htm
+const (
+	SpecialAppRoot    = "approot"
+	SpecialDocuments  = "documents"
+	SpecialPhotos     = "photos"
+	SpecialCameraRoll = "cameraroll"
+	SpecialMusic      = "music"
+)
+
+// Special returns the special folder with the given name, such as
+// SpecialDocuments, in the drive with the given ID. If no driveID is provided
+// the users default Drive is used. A special folder which does not exist yet
+// is created on first use.
+func (ds *DriveService) Special(driveID, name string) (*Item, *http.Response, error) {
+	return ds.SpecialContext(context.Background(), driveID, name)
+}
+
+// SpecialContext is like Special but carries a context and accepts query options.
+func (ds *DriveService) SpecialContext(ctx context.Context, driveID, name string, query ...*Query) (*Item, *http.Response, error) {
+	uri, err := withQuery(driveURIFromID(driveID)+"/special/"+url.PathEscape(name), itemType, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := ds.newRequest(ctx, "GET", uri, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	item := new(Item)
+	resp, err := ds.do(req, item)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return item, resp, nil
+}
+
+// Recent returns the items recently used by the authenticated user. Items
+// which live in another drive have their RemoteItem facet set.
+// See: http://onedrive.github.io/drives/recent_files.htm
+func (ds *DriveService) Recent() (*Items, *http.Response, error) {
+	return ds.RecentContext(context.Background())
+}
+
+// RecentContext is like Recent but carries a context and accepts query options.
+func (ds *DriveService) RecentContext(ctx context.Context, query ...*Query) (*Items, *http.Response, error) {
+	return ds.listView(ctx, "recent", query)
+}
+
+// SharedWithMe returns the items other users have shared with the
+// authenticated user. The RemoteItem facet of each item locates it in its
+// owner's drive.
+// See: http://onedrive.github.io/drives/shared_with_me.htm
+func (ds *DriveService) SharedWithMe() (*Items, *http.Response, error) {
+	return ds.SharedWithMeContext(context.Background())
+}
+
+// SharedWithMeContext is like SharedWithMe but carries a context and accepts query options.
+func (ds *DriveService) SharedWithMeContext(ctx context.Context, query ...*Query) (*Items, *http.Response, error) {
+	return ds.listView(ctx, "sharedWithMe", query)
+}
+
+// listView fetches one of the views of the default drive. The legacy API
+// names them view.{name}, whereas Graph exposes them as functions.
+func (ds *DriveService) listView(ctx context.Context, name string, query []*Query) (*Items, *http.Response, error) {
+	path := "/drive/view." + name
+	if ds.Graph {
+		path = "/drive/" + name
+	}
+
+	uri, err := withQuery(path, itemType, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := ds.newRequest(ctx, "GET", uri, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := new(Items)
+	resp, err := ds.do(req, items)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return items, resp, nil
+}
@@ -0,0 +1,98 @@
+package onedrive
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestSpecial(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/special/documents", fileWrapperHandler("fixtures/item.special.documents.json", http.StatusOK))
+	mux.HandleFunc("/drives/0123456789abc/special/photos", fileWrapperHandler("fixtures/request.invalid.notFound.json", http.StatusNotFound))
+
+	item, _, err := oneDrive.Drives.Special("", SpecialDocuments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.SpecialFolder == nil {
+		t.Fatal("Expected the specialFolder facet to be set")
+	}
+	if got, want := item.SpecialFolder.Name, SpecialDocuments; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+
+	if _, _, err := oneDrive.Drives.Special("0123456789abc", SpecialPhotos); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Got %v Expected %v", err, ErrNotFound)
+	}
+}
+
+func TestSharedWithMe(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/drive/view.sharedWithMe", fileWrapperHandler("fixtures/item.sharedWithMe.valid.json", http.StatusOK))
+
+	items, _, err := oneDrive.Drives.SharedWithMe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(items.Collection), 2; got != want {
+		t.Fatalf("Got %d Expected %d", got, want)
+	}
+
+	tt := []struct {
+		id, driveID string
+		folder      bool
+	}{
+		{"fedcba9876543!310", "fedcba9876543", false},
+		{"fedcba9876543!320", "fedcba9876543", true},
+	}
+	for i, tst := range tt {
+		remote := items.Collection[i].RemoteItem
+		if remote == nil {
+			t.Errorf("[%d] Expected the remoteItem facet to be set", i)
+			continue
+		}
+		if got, want := remote.ID, tst.id; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := remote.ParentReference.DriveID, tst.driveID; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := remote.Folder != nil, tst.folder; got != want {
+			t.Errorf("[%d] Got %t Expected %t", i, got, want)
+		}
+	}
+}
+
+func TestViewPaths(t *testing.T) {
+	tt := []struct {
+		graph       bool
+		view        func(*DriveService) (*Items, *http.Response, error)
+		expectedOut string
+	}{
+		{false, func(ds *DriveService) (*Items, *http.Response, error) { return ds.Recent() }, "/drive/view.recent"},
+		{true, func(ds *DriveService) (*Items, *http.Response, error) { return ds.Recent() }, "/me/drive/recent"},
+		{false, func(ds *DriveService) (*Items, *http.Response, error) { return ds.SharedWithMe() }, "/drive/view.sharedWithMe"},
+		{true, func(ds *DriveService) (*Items, *http.Response, error) { return ds.SharedWithMe() }, "/me/drive/sharedWithMe"},
+	}
+	for i, tst := range tt {
+		setup()
+		var path string
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			fileWrapperHandler("fixtures/item.children.valid.json", http.StatusOK)(w, r)
+		})
+		oneDrive.Graph = tst.graph
+		if _, _, err := tst.view(oneDrive.Drives); err != nil {
+			t.Errorf("[%d] %v", i, err)
+		}
+		if got, want := path, tst.expectedOut; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		teardown()
+	}
+}
@@ -1,39 +1,63 @@
 package onedrive
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 )
 
 const oneHundredMB = 104857600
 
 // UploadFromURL allows your app to upload an item to OneDrive by providing a URL.
 // OneDrive will download the file directly from a remote server so your app
-// doesn't have to upload the file's bytes.
+// doesn't have to upload the file's bytes. The upload happens asynchronously,
+// the returned AsyncJob can be used to Wait for the uploaded item.
 // See: http://onedrive.github.io/items/upload_url.htm
-func (is *ItemService) UploadFromURL(parentID, name, webURL string) (*Item, *http.Response, error) {
+func (is *ItemService) UploadFromURL(parentID, name, webURL string) (*AsyncJob, *http.Response, error) {
+	return is.UploadFromURLContext(context.Background(), parentID, name, webURL)
+}
+
+// UploadFromURLContext is like UploadFromURL but carries a context.
+func (is *ItemService) UploadFromURLContext(ctx context.Context, parentID, name, webURL string) (*AsyncJob, *http.Response, error) {
 	requestHeaders := map[string]string{
 		"Prefer": "respond-async",
 	}
 
 	newFile := newWebUpload{
-		webURL, name, new(FileFacet),
+		Name: name,
+		File: new(FileFacet),
+	}
+	if is.Graph {
+		newFile.GraphSourceURL = webURL
+		newFile.GraphConflictBehaviour = is.conflict
+	} else {
+		newFile.SourceURL = webURL
+		newFile.ConflictBehaviour = is.conflict
 	}
 
-	path := fmt.Sprintf("/drive/items/%s/children", parentID)
-	req, err := is.newRequest("POST", path, requestHeaders, newFile)
+	path := is.itemURI(parentID) + "/children"
+	req, err := is.newRequest(ctx, "POST", path, requestHeaders, newFile)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	item := new(Item)
-	resp, err := is.do(req, item)
+	resp, err := is.do(req, nil)
+	if err != nil {
+		return nil, resp, conflictError(err, name)
+	}
+
+	job, err := is.newAsyncJob(resp, is.driveID)
 	if err != nil {
 		return nil, resp, err
 	}
 
-	return item, resp, nil
+	return job, resp, nil
 }
 
 // SimpleUpload allows you to provide the contents of a new file or update the
@@ -41,6 +65,11 @@ func (is *ItemService) UploadFromURL(parentID, name, webURL string) (*Item, *htt
 // files up to 100MB in size. For larger files use ResumableUpload().
 // See: https://dev.onedrive.com/items/upload_put.htm
 func (is ItemService) SimpleUpload(folderID string, file *os.File) (*Item, *http.Response, error) {
+	return is.SimpleUploadContext(context.Background(), folderID, file)
+}
+
+// SimpleUploadContext is like SimpleUpload but carries a context.
+func (is ItemService) SimpleUploadContext(ctx context.Context, folderID string, file *os.File) (*Item, *http.Response, error) {
 	fileInfo, err := file.Stat()
 	if err != nil {
 		return nil, nil, err
@@ -50,9 +79,191 @@ func (is ItemService) SimpleUpload(folderID string, file *os.File) (*Item, *http
 		return nil, nil, ErrFileTooLarge
 	}
 
-	path := fmt.Sprintf("/drive/items/%s/children/%s/content", folderID, file.Name())
-	req, err := is.newRequest("PUT", path, nil, file)
+	name := filepath.Base(file.Name())
+	return is.uploadContent(ctx, is.childContentURI(folderID, name), name, file)
+}
+
+// Upload uploads the content read from r as a file named name within the
+// folder with the given folderID. size is the length of the content, or -1 if
+// it is not known, as for pipes or generated content. Content shorter than
+// the SimpleUploadThreshold of opts is sent in a single request; anything
+// longer goes through an upload session, read one fragment at a time so that
+// the content is never held in memory in full. Upload sessions need the total
+// size up front, so longer content of unknown size is first copied in full to
+// a temporary file in the SpoolDir of opts, taking as much disk space as the
+// content itself. At most SpoolLimit bytes, 1GiB by default, are copied;
+// longer content fails with ErrUnknownSize. Pass the size when it is known to
+// avoid the temporary file altogether.
+func (is *ItemService) Upload(folderID, name string, r io.Reader, size int64, opts *UploadOptions) (*Item, *http.Response, error) {
+	return is.UploadContext(context.Background(), folderID, name, r, size, opts)
+}
+
+// UploadContext is like Upload but carries a context.
+func (is *ItemService) UploadContext(ctx context.Context, folderID, name string, r io.Reader, size int64, opts *UploadOptions) (*Item, *http.Response, error) {
+	threshold := opts.simpleUploadThreshold()
+	if size >= 0 && size < threshold {
+		content := make([]byte, size)
+		if _, err := io.ReadFull(r, content); err != nil {
+			return nil, nil, err
+		}
+		return is.uploadContent(ctx, is.childContentURI(folderID, name), name, bytes.NewReader(content))
+	}
+
+	if size < 0 {
+		// Read ahead to find out whether the content fits a simple upload.
+		// The buffer grows as the content arrives, so short content does not
+		// cost a buffer of the full threshold.
+		head := new(bytes.Buffer)
+		_, err := io.CopyN(head, r, threshold)
+		switch err {
+		case io.EOF:
+			return is.uploadContent(ctx, is.childContentURI(folderID, name), name, bytes.NewReader(head.Bytes()))
+		case nil:
+			return is.uploadSpooled(ctx, folderID, name, io.MultiReader(head, r), opts)
+		default:
+			return nil, nil, err
+		}
+	}
+
+	session, resp, err := is.CreateUploadSessionContext(ctx, folderID, name, is.conflictFor(opts))
+	if err != nil {
+		return nil, resp, conflictError(err, name)
+	}
+
+	item, resp, err := is.uploadStream(ctx, session, r, size, opts)
+	return item, resp, conflictError(err, name)
+}
+
+// uploadSpooled uploads content of unknown size by copying it to a temporary
+// file first, so that the upload session can be told its total size.
+func (is *ItemService) uploadSpooled(ctx context.Context, folderID, name string, r io.Reader, opts *UploadOptions) (*Item, *http.Response, error) {
+	limit := opts.spoolLimit()
+	if limit < 0 {
+		return nil, nil, ErrUnknownSize
+	}
+
+	spool, err := os.CreateTemp(opts.spoolDir(), "onedrive-upload-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	size, err := io.Copy(spool, io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if size > limit {
+		return nil, nil, ErrUnknownSize
+	}
+
+	session, resp, err := is.CreateUploadSessionContext(ctx, folderID, name, is.conflictFor(opts))
+	if err != nil {
+		return nil, resp, conflictError(err, name)
+	}
+
+	item, resp, err := is.uploadToSession(ctx, session, spool, size, opts, nil)
+	return item, resp, conflictError(err, name)
+}
+
+// uploadStream uploads the size bytes read from r to the session. Each fragment
+// is buffered so that it can be sent again, from wherever the service stopped
+// receiving it, if it fails, and hashed as it is read in case VerifyHashes is
+// set.
+func (is *ItemService) uploadStream(ctx context.Context, session *UploadSession, r io.Reader, size int64, opts *UploadOptions) (*Item, *http.Response, error) {
+	br := bufio.NewReader(r)
+	fragment := make([]byte, opts.chunkSize())
+	hasher := NewHasher()
+	var resp *http.Response
+	for offset := int64(0); ; {
+		n, err := io.ReadFull(br, fragment)
+		hasher.Write(fragment[:n])
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return nil, resp, err
+		}
+		if !last {
+			// Peek to find out whether the stream ends with this fragment.
+			if _, err := br.Peek(1); err == io.EOF {
+				last = true
+			} else if err != nil {
+				return nil, resp, err
+			}
+		}
+		if last && offset+int64(n) != size {
+			return nil, resp, fmt.Errorf("onedrive: read %d bytes of %d byte upload: %w", offset+int64(n), size, io.ErrUnexpectedEOF)
+		}
+
+		var item *Item
+		item, resp, err = is.uploadBuffered(ctx, session, fragment[:n], offset, size, opts)
+		if err != nil {
+			return nil, resp, err
+		}
+		if item != nil {
+			if is.VerifyHashes {
+				if err := verifyHashes(itemHashes(item), hasher.Hashes()); err != nil {
+					return nil, resp, err
+				}
+			}
+			return item, resp, nil
+		}
+		if last {
+			return nil, resp, fmt.Errorf("onedrive: upload session expects more than %d bytes", size)
+		}
+		offset += int64(n)
+	}
+}
+
+// uploadBuffered uploads a fragment held in memory, starting at offset of the
+// file, until the service has received all of it.
+func (is *ItemService) uploadBuffered(ctx context.Context, session *UploadSession, fragment []byte, offset, size int64, opts *UploadOptions) (*Item, *http.Response, error) {
+	sent := int64(0)
+	for failures := 1; ; failures++ {
+		length := int64(len(fragment)) - sent
+		item, resp, err := is.UploadFragmentContext(ctx, session, bytes.NewReader(fragment[sent:]), offset+sent, length, size)
+		if err == nil {
+			return item, resp, nil
+		}
+
+		if resp, err := is.recoverSession(ctx, session, err, failures, opts); err != nil {
+			return nil, resp, err
+		}
+		start, _, err := session.nextRange()
+		if err != nil {
+			return nil, resp, err
+		}
+		if start == offset+int64(len(fragment)) {
+			// The whole fragment was received after all.
+			return nil, resp, nil
+		}
+		if start < offset || start > offset+int64(len(fragment)) {
+			return nil, resp, fmt.Errorf("onedrive: cannot resume a streamed upload from byte %d", start)
+		}
+		sent = start - offset
+	}
+}
+
+// childContentURI returns the request URI of the content of the file named
+// name within the folder with the given folderID.
+func (is *ItemService) childContentURI(folderID, name string) string {
+	return fmt.Sprintf("%s/children/%s/content", is.itemURI(folderID), url.PathEscape(name))
+}
+
+// uploadContent replaces the contents of the item named name at the given
+// content URI.
+func (is ItemService) uploadContent(ctx context.Context, uri, name string, content io.ReadSeeker) (*Item, *http.Response, error) {
+	var local *HashesFacet
+	if is.VerifyHashes {
+		var err error
+		if local, err = hashReadSeeker(content); err != nil {
+			return nil, nil, err
+		}
+	}
 
+	requestHeaders := map[string]string{
+		"Content-Type": "application/octet-stream",
+	}
+	req, err := is.newRequest(ctx, "PUT", is.withConflict(uri), requestHeaders, content)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -60,7 +271,13 @@ func (is ItemService) SimpleUpload(folderID string, file *os.File) (*Item, *http
 	item := new(Item)
 	resp, err := is.do(req, item)
 	if err != nil {
-		return nil, resp, err
+		return nil, resp, conflictError(err, name)
+	}
+
+	if local != nil {
+		if err := verifyHashes(itemHashes(item), local); err != nil {
+			return nil, resp, err
+		}
 	}
 
 	return item, resp, nil
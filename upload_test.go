@@ -0,0 +1,171 @@
+package onedrive
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// streamReader hides everything but Read, as for a pipe.
+type streamReader struct {
+	r io.Reader
+}
+
+func (sr streamReader) Read(p []byte) (int, error) {
+	return sr.r.Read(p)
+}
+
+func TestSimpleUploadName(t *testing.T) {
+	setup()
+	defer teardown()
+
+	path := filepath.Join(t.TempDir(), "q3 report.txt")
+	if err := os.WriteFile(path, []byte(downloadContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var requestURI string
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		fileWrapperHandler("fixtures/item.download.valid.json", http.StatusCreated)(w, r)
+	})
+
+	if _, _, err := oneDrive.Items.SimpleUpload("101", file); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := requestURI, "/drive/items/101/children/q3%20report.txt/content"; got != want {
+		t.Errorf("Got %q Expected %q", got, want)
+	}
+}
+
+func TestUploadSimple(t *testing.T) {
+	tt := []struct {
+		content string
+		size    int64
+	}{
+		{downloadContent, -1},
+		{downloadContent, int64(len(downloadContent))},
+		{"", -1},
+	}
+	for i, tst := range tt {
+		setup()
+		var method, contentType, body string
+		mux.HandleFunc("/drive/items/101/children/alphabet.txt/content", func(w http.ResponseWriter, r *http.Request) {
+			method = r.Method
+			contentType = r.Header.Get("Content-Type")
+			b, _ := io.ReadAll(r.Body)
+			body = string(b)
+			fileWrapperHandler("fixtures/item.download.valid.json", http.StatusCreated)(w, r)
+		})
+
+		r := streamReader{strings.NewReader(tst.content)}
+		if _, _, err := oneDrive.Items.Upload("101", "alphabet.txt", r, tst.size, nil); err != nil {
+			t.Errorf("[%d] %v", i, err)
+		}
+		if got, want := method, "PUT"; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := contentType, "application/octet-stream"; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if got, want := body, tst.content; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		teardown()
+	}
+}
+
+func TestUploadStream(t *testing.T) {
+	tt := []struct {
+		known         bool
+		failAt        int64
+		contentRanges []string
+	}{
+		{false, -1, []string{
+			"bytes 0-327679/1048676",
+			"bytes 327680-655359/1048676",
+			"bytes 655360-983039/1048676",
+			"bytes 983040-1048675/1048676",
+		}},
+		{true, -1, []string{
+			"bytes 0-327679/1048676",
+			"bytes 327680-655359/1048676",
+			"bytes 655360-983039/1048676",
+			"bytes 983040-1048675/1048676",
+		}},
+		{false, 327680, []string{
+			"bytes 0-327679/1048676",
+			"bytes 327680-655359/1048676",
+			"bytes 491520-819199/1048676",
+			"bytes 819200-1048675/1048676",
+		}},
+	}
+	for i, tst := range tt {
+		setup()
+		content, file := largeFile(t, 1048676)
+		ss := &sessionServer{failAt: tst.failAt}
+		mux.HandleFunc("/drive/root:/large.bin:/upload.createSession", fileTemplateHandler("fixtures/session.created.json", http.StatusOK))
+		mux.Handle("/upload/session1", ss)
+		oneDrive.RetryPolicy = fastRetryPolicy()
+
+		size := int64(-1)
+		if tst.known {
+			size = int64(len(content))
+		}
+		spoolDir := t.TempDir()
+		opts := &UploadOptions{ChunkSize: 320 * 1024, SimpleUploadThreshold: 320 * 1024, SpoolDir: spoolDir}
+		item, _, err := oneDrive.Items.Upload("root", "large.bin", streamReader{file}, size, opts)
+		if err != nil {
+			t.Errorf("[%d] %v", i, err)
+			teardown()
+			continue
+		}
+		if got, want := item.Name, "large.bin"; got != want {
+			t.Errorf("[%d] Got %q Expected %q", i, got, want)
+		}
+		if !bytes.Equal(ss.data, content) {
+			t.Errorf("[%d] Expected the uploaded content to match the stream", i)
+		}
+		if got, want := ss.contentRanges, tst.contentRanges; !reflect.DeepEqual(got, want) {
+			t.Errorf("[%d] Got %v Expected %v", i, got, want)
+		}
+		if entries, err := os.ReadDir(spoolDir); err != nil || len(entries) != 0 {
+			t.Errorf("[%d] Expected the spool directory to be left empty, got %v %v", i, entries, err)
+		}
+		teardown()
+	}
+}
+
+func TestUploadUnknownSizeOverSpoolLimit(t *testing.T) {
+	tt := []int64{-1, 640 * 1024}
+	for i, limit := range tt {
+		setup()
+		_, file := largeFile(t, 1048676)
+		sessions := 0
+		mux.HandleFunc("/drive/root:/large.bin:/upload.createSession", func(w http.ResponseWriter, r *http.Request) {
+			sessions++
+			fileTemplateHandler("fixtures/session.created.json", http.StatusOK)(w, r)
+		})
+
+		opts := &UploadOptions{SimpleUploadThreshold: 320 * 1024, SpoolLimit: limit}
+		_, _, err := oneDrive.Items.Upload("root", "large.bin", streamReader{file}, -1, opts)
+		if !errors.Is(err, ErrUnknownSize) {
+			t.Errorf("[%d] Got %v Expected %v", i, err, ErrUnknownSize)
+		}
+		if sessions != 0 {
+			t.Errorf("[%d] Expected no upload session to be created", i)
+		}
+		teardown()
+	}
+}